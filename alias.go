@@ -0,0 +1,87 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+// Alias adds aliases, other names by which the named EnvVar may be set,
+// to the EnvVar, which must already be defined. Names are given without
+// the set's prefix. When several of an EnvVar's names are present in the
+// environment, the EnvVar's Name takes precedence, followed by its aliases
+// in the order they were added; the others are ignored. The EnvVar's
+// UsedName records the name that supplied its value.
+func (evs *EnvVarSet) Alias(name string, aliases ...string) {
+	evs.addAliases(name, aliases, false)
+}
+
+// Alias adds aliases to the named EnvVar in the default set.
+func Alias(name string, aliases ...string) {
+	EnvVars.Alias(name, aliases...)
+}
+
+// DeprecatedAlias adds aliases to the named EnvVar as Alias does, marking
+// them as deprecated: setting the EnvVar by one of them during a parse
+// writes a warning to the set's output.
+func (evs *EnvVarSet) DeprecatedAlias(name string, aliases ...string) {
+	evs.addAliases(name, aliases, true)
+}
+
+// DeprecatedAlias adds deprecated aliases to the named EnvVar in the
+// default set.
+func DeprecatedAlias(name string, aliases ...string) {
+	EnvVars.DeprecatedAlias(name, aliases...)
+}
+
+func (evs *EnvVarSet) addAliases(name string, aliases []string, deprecated bool) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	envVar := evs.lookupDefined(name)
+	for _, alias := range aliases {
+		alias = evs.prefix + alias
+		if evs.resolve(alias) != nil {
+			evs.definitionPanic("EnvVar redefined: " + alias)
+		}
+		if evs.aliases == nil {
+			evs.aliases = make(map[string]*EnvVar)
+		}
+		evs.aliases[alias] = envVar
+		envVar.Aliases = append(envVar.Aliases, alias)
+		if deprecated {
+			if envVar.deprecated == nil {
+				envVar.deprecated = make(map[string]bool)
+			}
+			envVar.deprecated[alias] = true
+		}
+	}
+}
+
+// resolve returns the EnvVar with the given name or alias, or nil if there
+// is none.
+func (evs *EnvVarSet) resolve(name string) *EnvVar {
+	if envVar, ok := evs.formal[name]; ok {
+		return envVar
+	}
+	return evs.aliases[name]
+}
+
+// shadowed reports whether a name of envVar with precedence over name is
+// present, directly or through the file suffix, in env.
+func (evs *EnvVarSet) shadowed(envVar *EnvVar, name string, env map[string]string) bool {
+	if name == envVar.Name {
+		return false
+	}
+	for _, n := range append([]string{envVar.Name}, envVar.Aliases...) {
+		if n == name {
+			return false
+		}
+		if _, ok := env[n]; ok {
+			return true
+		}
+		if evs.fileSuffix != "" {
+			if _, ok := env[n+evs.fileSuffix]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestAlias(t *testing.T) {
+	tests := []struct {
+		env      []string
+		want     string
+		usedName string
+	}{
+		{[]string{"DATABASE_HOST=new"}, "new", "DATABASE_HOST"},
+		{[]string{"DB_HOST=old"}, "old", "DB_HOST"},
+		{[]string{"DB_HOST=old", "DATABASE_HOST=new"}, "new", "DATABASE_HOST"},
+		{[]string{"DATABASE_HOST=new", "DB_HOST=old"}, "new", "DATABASE_HOST"},
+		{[]string{"HOST=oldest", "DB_HOST=old"}, "old", "DB_HOST"},
+		{[]string{"DB_HOST=old", "HOST=oldest"}, "old", "DB_HOST"},
+		{nil, "default", ""},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		host := evs.String("DATABASE_HOST", "default")
+		evs.Alias("DATABASE_HOST", "DB_HOST", "HOST")
+		if err := evs.Parse(test.env); err != nil {
+			t.Fatal(err)
+		}
+		if *host != test.want {
+			t.Errorf("Parse(%q): host = %q, want %q", test.env, *host, test.want)
+		}
+		if ev := evs.Lookup("DB_HOST"); ev == nil || ev.UsedName != test.usedName {
+			t.Errorf("Parse(%q): Lookup(DB_HOST) = %+v, want UsedName %q", test.env, ev, test.usedName)
+		}
+	}
+}
+
+func TestAliasVisitAndSet(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetPrefix("APP_")
+	host := evs.String("DATABASE_HOST", "")
+	evs.Alias("DATABASE_HOST", "DB_HOST")
+	if err := evs.Set("DB_HOST", "x"); err != nil || *host != "x" {
+		t.Fatalf("Set(DB_HOST, x) = %v, host %q", err, *host)
+	}
+	var visited []string
+	evs.Visit(func(ev *EnvVar) { visited = append(visited, ev.Name+" "+ev.UsedName) })
+	if len(visited) != 1 || visited[0] != "APP_DATABASE_HOST APP_DB_HOST" {
+		t.Errorf("Visit saw %q", visited)
+	}
+	evs.VisitAll(func(ev *EnvVar) {
+		if ev.Name != "APP_DATABASE_HOST" {
+			t.Errorf("VisitAll saw alias %s", ev.Name)
+		}
+	})
+}
+
+func TestDeprecatedAlias(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var out bytes.Buffer
+	evs.SetOutput(&out)
+	evs.String("DATABASE_HOST", "")
+	evs.DeprecatedAlias("DATABASE_HOST", "DB_HOST")
+	if err := evs.Parse([]string{"DATABASE_HOST=new"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("unexpected warning %q", out.String())
+	}
+	if err := evs.Parse([]string{"DB_HOST=old"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "env var DB_HOST is deprecated, use DATABASE_HOST instead\n"; out.String() != want {
+		t.Errorf("warning = %q, want %q", out.String(), want)
+	}
+}
+
+func TestAliasFileSuffix(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := ioutil.WriteFile(secret, []byte("fromfile"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetFileSuffix("_FILE")
+	password := evs.String("DATABASE_PASSWORD", "")
+	evs.Alias("DATABASE_PASSWORD", "DB_PASSWORD")
+	if err := evs.Parse([]string{"DB_PASSWORD=old", "DATABASE_PASSWORD_FILE=" + secret}); err != nil {
+		t.Fatal(err)
+	}
+	if *password != "fromfile" {
+		t.Errorf("password = %q, want fromfile", *password)
+	}
+	if ev := evs.Lookup("DATABASE_PASSWORD"); ev.UsedName != "DATABASE_PASSWORD_FILE" {
+		t.Errorf("UsedName = %q, want DATABASE_PASSWORD_FILE", ev.UsedName)
+	}
+}
+
+func TestAliasRedefinedPanics(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.String("A", "")
+	evs.String("B", "")
+	defer func() {
+		if recover() == nil {
+			t.Error("alias with the name of another env var did not panic")
+		}
+	}()
+	evs.Alias("A", "B")
+}
@@ -0,0 +1,198 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Bind defines an EnvVar for each field of the struct pointed to by v that
+// has an env tag, such as
+//
+//	Port int `env:"PORT"`
+//
+// The tag value is the name of the EnvVar and the field's current value is
+// its default. The name may be followed by the options ",required" to mark
+// the EnvVar as required and ",secret" to mark it as secret, and a usage tag
+// gives the EnvVar's description. Fields of type Secret are always secret.
+// Untagged struct fields are walked recursively; other untagged fields are
+// ignored.
+// Fields may be of any type supported by the Var functions of EnvVarSet,
+// any type whose pointer satisfies Value, or any type whose pointer
+// satisfies both encoding.TextUnmarshaler and encoding.TextMarshaler.
+//
+// Bind returns an error, and defines no EnvVars, if v is not a non-nil
+// pointer to a struct, if a tagged field is unexported or of an unsupported
+// type, or if a tag names an EnvVar already defined or named by another
+// field.
+func (evs *EnvVarSet) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("envvar: Bind requires a non-nil pointer to a struct, got %T", v)
+	}
+	var defs []func()
+	if err := evs.bindStruct(rv.Elem(), &defs, make(map[string]bool)); err != nil {
+		return err
+	}
+	for _, def := range defs {
+		def()
+	}
+	return nil
+}
+
+// Bind defines an EnvVar in the default set for each tagged field of the
+// struct pointed to by v. See EnvVarSet.Bind for details.
+func Bind(v interface{}) error {
+	return EnvVars.Bind(v)
+}
+
+// bindStruct appends to defs a definition for each tagged field of the
+// struct sv, recording the names defined in names. Nothing is defined until
+// every field has been checked so that an unsupported field leaves the set
+// untouched.
+func (evs *EnvVarSet) bindStruct(sv reflect.Value, defs *[]func(), names map[string]bool) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		tag, tagged := field.Tag.Lookup("env")
+		if !tagged {
+			if field.Type.Kind() == reflect.Struct && field.PkgPath == "" {
+				if err := evs.bindStruct(sv.Field(i), defs, names); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, opts := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, opts = tag[:comma], tag[comma+1:]
+		}
+		if name == "" {
+			return fmt.Errorf("envvar: field %s.%s has an empty env tag", st, field.Name)
+		}
+		required, secret := false, false
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "":
+			case "required":
+				required = true
+			case "secret":
+				secret = true
+			default:
+				return fmt.Errorf("envvar: field %s.%s has unknown env tag option %q", st, field.Name, opt)
+			}
+		}
+		if field.PkgPath != "" {
+			return fmt.Errorf("envvar: field %s.%s for env var %s is unexported", st, field.Name, name)
+		}
+		if names[name] {
+			return fmt.Errorf("envvar: field %s.%s names env var %s, which another field also names", st, field.Name, name)
+		}
+		if evs.Lookup(name) != nil {
+			return fmt.Errorf("envvar: field %s.%s names env var %s, which is already defined", st, field.Name, name)
+		}
+		names[name] = true
+		def := evs.bindField(sv.Field(i).Addr().Interface(), name)
+		if def == nil {
+			return fmt.Errorf("envvar: field %s.%s for env var %s has unsupported type %s", st, field.Name, name, field.Type)
+		}
+		usage := field.Tag.Get("usage")
+		if required || secret || usage != "" {
+			define := def
+			def = func() {
+				define()
+				evs.SetUsage(name, usage)
+				if required {
+					evs.Required(name)
+				}
+				if secret {
+					evs.Redact(name)
+				}
+			}
+		}
+		*defs = append(*defs, def)
+	}
+	return nil
+}
+
+// bindField returns a function defining an EnvVar named name for the field
+// pointed to by p, or nil if the field's type is not supported.
+func (evs *EnvVarSet) bindField(p interface{}, name string) func() {
+	switch p := p.(type) {
+	case *Secret:
+		return func() { evs.SecretVar(p, name, p.Reveal()) }
+	case Value:
+		return func() { evs.Var(p, name) }
+	case *bool:
+		return func() { evs.BoolVar(p, name, *p) }
+	case *int:
+		return func() { evs.IntVar(p, name, *p) }
+	case *int8:
+		return func() { evs.Int8Var(p, name, *p) }
+	case *int16:
+		return func() { evs.Int16Var(p, name, *p) }
+	case *int32:
+		return func() { evs.Int32Var(p, name, *p) }
+	case *int64:
+		return func() { evs.Int64Var(p, name, *p) }
+	case *uint:
+		return func() { evs.UintVar(p, name, *p) }
+	case *uint8:
+		return func() { evs.Uint8Var(p, name, *p) }
+	case *uint16:
+		return func() { evs.Uint16Var(p, name, *p) }
+	case *uint32:
+		return func() { evs.Uint32Var(p, name, *p) }
+	case *uint64:
+		return func() { evs.Uint64Var(p, name, *p) }
+	case *string:
+		return func() { evs.StringVar(p, name, *p) }
+	case *float32:
+		return func() { evs.Float32Var(p, name, *p) }
+	case *float64:
+		return func() { evs.Float64Var(p, name, *p) }
+	case *complex128:
+		return func() { evs.Complex128Var(p, name, *p) }
+	case *big.Int:
+		return func() { evs.BigIntVar(p, name, p) }
+	case *big.Float:
+		return func() { evs.BigFloatVar(p, name, p) }
+	case *time.Duration:
+		return func() { evs.DurationVar(p, name, *p) }
+	case *[]string:
+		return func() { evs.StringSliceVar(p, name, *p) }
+	case *[]int:
+		return func() { evs.IntSliceVar(p, name, *p) }
+	case *[]float64:
+		return func() { evs.Float64SliceVar(p, name, *p) }
+	case *[]time.Duration:
+		return func() { evs.DurationSliceVar(p, name, *p) }
+	case *map[string]string:
+		return func() { evs.StringToStringVar(p, name, *p) }
+	case *map[string]int:
+		return func() { evs.StringToIntVar(p, name, *p) }
+	case *url.URL:
+		return func() { evs.URLVar(p, name, p) }
+	case *net.IP:
+		return func() { evs.IPVar(p, name, *p) }
+	case *net.IPNet:
+		return func() { evs.IPNetVar(p, name, *p) }
+	case *netip.AddrPort:
+		return func() { evs.AddrPortVar(p, name, *p) }
+	case encoding.TextUnmarshaler:
+		if m, ok := p.(encoding.TextMarshaler); ok {
+			return func() { evs.TextVar(p, name, m) }
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/dyson/envvar"
+)
+
+type bindDB struct {
+	URL     string        `env:"DB_URL"`
+	Timeout time.Duration `env:"DB_TIMEOUT"`
+}
+
+type bindConf struct {
+	Port    int       `env:"PORT"`
+	Debug   bool      `env:"DEBUG"`
+	Ratio   float64   `env:"RATIO"`
+	Max     int64     `env:"MAX"`
+	Workers uint      `env:"WORKERS"`
+	Limit   uint64    `env:"LIMIT"`
+	Users   userVar   `env:"USERS"`
+	Since   time.Time `env:"SINCE"`
+	Bind    net.IP    `env:"BIND"`
+	Ignored string
+	DB      bindDB
+}
+
+func TestBind(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	conf := &bindConf{Port: 80, Ratio: 0.5, DB: bindDB{Timeout: time.Second}}
+	if err := evs.Bind(conf); err != nil {
+		t.Fatal(err)
+	}
+	if ev := evs.Lookup("PORT"); ev == nil || ev.Value.String() != "80" {
+		t.Errorf("PORT not bound with default 80: %v", ev)
+	}
+	if ev := evs.Lookup("Ignored"); ev != nil {
+		t.Error("untagged field was bound")
+	}
+	args := []string{
+		"PORT=8080",
+		"DEBUG=true",
+		"MAX=-3",
+		"WORKERS=4",
+		"LIMIT=5",
+		"USERS=alice",
+		"DB_URL=postgres://db",
+		"SINCE=2017-01-02T03:04:05Z",
+		"BIND=10.0.0.1",
+	}
+	if err := evs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Port != 8080 || !conf.Debug || conf.Max != -3 || conf.Workers != 4 || conf.Limit != 5 {
+		t.Errorf("unexpected values after Parse: %+v", conf)
+	}
+	if conf.Ratio != 0.5 {
+		t.Errorf("ratio should keep default 0.5, is %v", conf.Ratio)
+	}
+	if len(conf.Users) != 1 || conf.Users[0] != "alice" {
+		t.Errorf("users should be [alice], is %v", conf.Users)
+	}
+	if conf.DB.URL != "postgres://db" || conf.DB.Timeout != time.Second {
+		t.Errorf("nested struct not bound: %+v", conf.DB)
+	}
+	if want := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC); !conf.Since.Equal(want) {
+		t.Errorf("since should be %v, is %v", want, conf.Since)
+	}
+	if !conf.Bind.Equal(net.IPv4(10, 0, 0, 1)) || evs.Lookup("BIND").Value.String() != "10.0.0.1" {
+		t.Errorf("bind should be 10.0.0.1, is %v", conf.Bind)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		v    interface{}
+		want string
+	}{
+		{bindConf{}, "pointer to a struct"},
+		{(*bindConf)(nil), "pointer to a struct"},
+		{&struct {
+			A int       `env:"A"`
+			B complex64 `env:"B"`
+		}{}, "unsupported type complex64"},
+		{&struct {
+			a int `env:"A"`
+		}{}, "unexported"},
+		{&struct {
+			A int `env:""`
+		}{}, "empty env tag"},
+		{&struct {
+			A int `env:"A,optional"`
+		}{}, "unknown env tag option"},
+		{&struct {
+			A int    `env:"A"`
+			B string `env:"A"`
+		}{}, "which another field also names"},
+		{&struct {
+			A  int `env:"A"`
+			DB struct {
+				A int `env:"A"`
+			}
+		}{}, "which another field also names"},
+		{&struct {
+			A int `env:"A"`
+			B int `env:"EXISTING"`
+		}{}, "which is already defined"},
+		{&struct {
+			A int `env:"A"`
+			B int `env:"OLD_EXISTING"`
+		}{}, "which is already defined"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.Int("EXISTING", 0)
+		evs.Alias("EXISTING", "OLD_EXISTING")
+		err := evs.Bind(test.v)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Bind(%T): want error containing %q, got %v", test.v, test.want, err)
+		}
+		if evs.Lookup("A") != nil {
+			t.Errorf("Bind(%T) defined env vars despite error", test.v)
+		}
+	}
+}
+
+func TestBindRequired(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	conf := &struct {
+		URL string `env:"DB_URL,required"`
+	}{}
+	if err := evs.Bind(conf); err != nil {
+		t.Fatal(err)
+	}
+	if !evs.Lookup("DB_URL").Required {
+		t.Error("DB_URL not marked required")
+	}
+	if err := evs.Parse(nil); err == nil {
+		t.Error("want error for missing DB_URL")
+	}
+}
+
+func TestBindUsage(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	conf := &struct {
+		Port int `env:"PORT" usage:"port to listen on"`
+	}{}
+	if err := evs.Bind(conf); err != nil {
+		t.Fatal(err)
+	}
+	if usage := evs.Lookup("PORT").Usage; usage != "port to listen on" {
+		t.Errorf("PORT usage should be %q, is %q", "port to listen on", usage)
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// ParseFile parses the env var definitions in the named dotenv file. See
+// ParseReader for the syntax of the file.
+func (evs *EnvVarSet) ParseFile(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		evs.mu.Lock()
+		defer evs.mu.Unlock()
+		evs.parsed = true
+		return evs.handleError(evs.fail(err))
+	}
+	defer f.Close()
+	return evs.parseDotenv(f, filename)
+}
+
+// ParseFile parses the env var definitions in the named dotenv file into
+// the default set.
+func ParseFile(filename string) error {
+	return EnvVars.ParseFile(filename)
+}
+
+// ParseReader parses env var definitions read from r in the dotenv format,
+// in which each line holds a definition, a comment or nothing:
+//
+//	# comment
+//	PORT=8080
+//	export HOST = example.com  # comment
+//	GREETING='literal $text, spanning
+//	lines'
+//	MOTD="escaped\ttext with \"quotes\"\n"
+//
+// Definitions may be prefixed with export. Unquoted values are trimmed and
+// end at a # preceded by whitespace. Single-quoted values are taken
+// literally and double-quoted values may contain the escapes \n, \r, \t,
+// \", \\ and \$; both may span lines.
+//
+// Malformed lines are reported by a *SyntaxError. The definitions are
+// parsed as by Parse, with errors giving the line of the definition, except
+// that required EnvVars are not checked: a dotenv file usually supplies
+// values ahead of a call to Parse, which does check them.
+func (evs *EnvVarSet) ParseReader(r io.Reader) error {
+	return evs.parseDotenv(r, "")
+}
+
+// ParseReader parses env var definitions read from r in the dotenv format
+// into the default set.
+func ParseReader(r io.Reader) error {
+	return EnvVars.ParseReader(r)
+}
+
+func (evs *EnvVarSet) parseDotenv(r io.Reader, filename string) error {
+	entries, err := readDotenv(r, filename)
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	if err != nil {
+		evs.parsed = true
+		return evs.handleError(evs.fail(err))
+	}
+	return evs.parseEntries(entries, os.LookupEnv, false, false)
+}
+
+// readDotenv returns the definitions read from r in the dotenv format.
+// The name of the file, if any, is used in error messages.
+func readDotenv(r io.Reader, filename string) ([]entry, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &dotenvParser{filename: filename, src: string(b), line: 1}
+	return p.parse()
+}
+
+// A dotenvParser holds the state of the parse of a dotenv file.
+type dotenvParser struct {
+	filename string
+	src      string
+	pos      int
+	line     int
+}
+
+// errorf returns a *SyntaxError at the current line.
+func (p *dotenvParser) errorf(format string, a ...interface{}) error {
+	return &SyntaxError{File: p.filename, Line: p.line, Msg: fmt.Sprintf(format, a...)}
+}
+
+// location describes a line of the named file, or of unnamed input.
+func location(filename string, line int) string {
+	if filename == "" {
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("%s:%d", filename, line)
+}
+
+func (p *dotenvParser) parse() ([]entry, error) {
+	var entries []entry
+	for {
+		p.skipBlanks()
+		if p.pos == len(p.src) {
+			return entries, nil
+		}
+		switch p.src[p.pos] {
+		case '\n':
+			p.pos++
+			p.line++
+			continue
+		case '#':
+			p.skipLine()
+			continue
+		}
+		line := p.line
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		value, tmpl, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{name: name, value: value, kind: OriginDotenv, file: p.filename, line: line, tmpl: tmpl})
+	}
+}
+
+func (p *dotenvParser) skipBlanks() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t' || p.src[p.pos] == '\r') {
+		p.pos++
+	}
+}
+
+// skipLine skips to the start of the next line.
+func (p *dotenvParser) skipLine() {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		p.pos += i + 1
+		p.line++
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+// word returns the text up to the next blank, newline or equals sign.
+func (p *dotenvParser) word() string {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(" \t\r\n=", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// name returns the name of a definition, consuming the equals sign that
+// follows it.
+func (p *dotenvParser) name() (string, error) {
+	name := p.word()
+	if name == "export" {
+		p.skipBlanks()
+		if p.pos < len(p.src) && p.src[p.pos] != '=' {
+			name = p.word()
+		}
+	}
+	if name == "" {
+		return "", p.errorf("missing variable name")
+	}
+	p.skipBlanks()
+	if p.pos == len(p.src) || p.src[p.pos] != '=' {
+		return "", p.errorf("missing = after %s", name)
+	}
+	p.pos++
+	return name, nil
+}
+
+// value returns the value of a definition, consuming the rest of its last
+// line. For a quoted value it also returns the template expanded in its
+// place by SetExpand, in which the text that is not to be expanded has its
+// $ doubled.
+func (p *dotenvParser) value() (value, tmpl string, err error) {
+	p.skipBlanks()
+	if p.pos == len(p.src) {
+		return "", "", nil
+	}
+	switch p.src[p.pos] {
+	case '\'':
+		end := strings.IndexByte(p.src[p.pos+1:], '\'')
+		if end < 0 {
+			return "", "", p.errorf("unterminated single-quoted value")
+		}
+		value = p.src[p.pos+1 : p.pos+1+end]
+		tmpl = strings.Replace(value, "$", "$$", -1)
+		p.line += strings.Count(value, "\n")
+		p.pos += end + 2
+	case '"':
+		if value, tmpl, err = p.doubleQuoted(); err != nil {
+			return "", "", err
+		}
+	default:
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+			if p.src[p.pos] == '#' && isSpace(p.src[p.pos-1]) {
+				break
+			}
+			p.pos++
+		}
+		value = strings.TrimSpace(p.src[start:p.pos])
+	}
+	p.skipBlanks()
+	if p.pos < len(p.src) && p.src[p.pos] != '\n' && p.src[p.pos] != '#' {
+		return "", "", p.errorf("unexpected text after value: %q", p.word())
+	}
+	p.skipLine()
+	return value, tmpl, nil
+}
+
+// doubleQuoted returns the double-quoted value starting at the current
+// position with its escapes replaced, and its template, in which an escaped
+// $ is doubled.
+func (p *dotenvParser) doubleQuoted() (string, string, error) {
+	line := p.line
+	var b, t strings.Builder
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), t.String(), nil
+		case '\n':
+			p.line++
+		case '\\':
+			if p.pos+1 == len(p.src) {
+				break
+			}
+			p.pos++
+			switch c = p.src[p.pos]; c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case '$':
+				t.WriteByte('$')
+			case '"', '\\':
+			default:
+				b.WriteByte('\\')
+				t.WriteByte('\\')
+			}
+		}
+		b.WriteByte(c)
+		t.WriteByte(c)
+	}
+	p.line = line
+	return "", "", p.errorf("unterminated double-quoted value")
+}
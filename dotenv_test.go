@@ -0,0 +1,119 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+const dotenv = `# comment
+PORT=8080
+
+export HOST = example.com  # trailing comment
+HASH=a#b
+EMPTY=
+SINGLE='literal $HOME \n # not a comment'
+DOUBLE="tab\there \"quoted\" \$HOME\\ # not a comment"
+MULTI="line one
+line two"
+MULTI_SINGLE='a
+b'
+UNDEFINED=ignored
+LAST=end`
+
+func TestParseReader(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	port := evs.Int("PORT", 0)
+	host := evs.String("HOST", "")
+	hash := evs.String("HASH", "")
+	empty := evs.String("EMPTY", "default")
+	single := evs.String("SINGLE", "")
+	double := evs.String("DOUBLE", "")
+	multi := evs.String("MULTI", "")
+	multiSingle := evs.String("MULTI_SINGLE", "")
+	last := evs.String("LAST", "")
+	evs.String("REQUIRED", "")
+	evs.Required("REQUIRED")
+	if err := evs.ParseReader(strings.NewReader(dotenv)); err != nil {
+		t.Fatal(err)
+	}
+	if !evs.Parsed() {
+		t.Error("Parsed() = false after ParseReader")
+	}
+	tests := []struct {
+		name, got, want string
+	}{
+		{"HOST", *host, "example.com"},
+		{"HASH", *hash, "a#b"},
+		{"EMPTY", *empty, ""},
+		{"SINGLE", *single, `literal $HOME \n # not a comment`},
+		{"DOUBLE", *double, "tab\there \"quoted\" $HOME\\ # not a comment"},
+		{"MULTI", *multi, "line one\nline two"},
+		{"MULTI_SINGLE", *multiSingle, "a\nb"},
+		{"LAST", *last, "end"},
+	}
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("%s = %q, want %q", test.name, test.got, test.want)
+		}
+	}
+	if *port != 8080 {
+		t.Errorf("PORT = %d, want 8080", *port)
+	}
+}
+
+func TestParseReaderErrors(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"A=1\nPORT=x\n", `line 2: invalid value "x" for env var PORT`},
+		{"A=1\n\nB\n", "line 3: missing = after B"},
+		{"=1", "line 1: missing variable name"},
+		{"A='x\n\n", "line 1: unterminated single-quoted value"},
+		{"A=\"x\ny\n", "line 1: unterminated double-quoted value"},
+		{"A='x' y", "line 1: unexpected text after value"},
+		{"A=\"x\ny\"\nPORT=z", "line 3: invalid value"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		evs.Int("PORT", 0)
+		err := evs.ParseReader(strings.NewReader(test.in))
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("ParseReader(%q): want error starting %q, got %v", test.in, test.want, err)
+		}
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), ".env")
+	if err := ioutil.WriteFile(filename, []byte("PORT=80\nDEBUG=maybe\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	port := evs.Int("PORT", 0)
+	evs.Bool("DEBUG", false)
+	err := evs.ParseFile(filename)
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.File != filename || perr.Line != 2 {
+		t.Fatalf("want *ParseError at %s:2, got %v", filename, err)
+	}
+	if !strings.HasPrefix(err.Error(), filename+":2: ") {
+		t.Errorf("error should start with %s:2, is %q", filename, err)
+	}
+	if *port != 0 {
+		t.Errorf("PORT = %d, want 0 after failed parse", *port)
+	}
+	if err := evs.ParseFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ParseFile of missing file succeeded")
+	}
+}
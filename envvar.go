@@ -46,11 +46,18 @@ are	analogous to the top-level functions for the default	envvar set.
 package envvar
 
 import (
+	"bytes"
+	"encoding"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 )
 
@@ -64,8 +71,11 @@ func newBoolValue(val bool, p *bool) *boolValue {
 
 func (b *boolValue) Set(s string) error {
 	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
 	*b = boolValue(v)
-	return err
+	return nil
 }
 
 func (b *boolValue) Get() interface{} { return bool(*b) }
@@ -82,8 +92,11 @@ func newIntValue(val int, p *int) *intValue {
 
 func (i *intValue) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
 	*i = intValue(v)
-	return err
+	return nil
 }
 
 func (i *intValue) Get() interface{} { return int(*i) }
@@ -100,8 +113,11 @@ func newInt64Value(val int64, p *int64) *int64Value {
 
 func (i *int64Value) Set(s string) error {
 	v, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
 	*i = int64Value(v)
-	return err
+	return nil
 }
 
 func (i *int64Value) Get() interface{} { return int64(*i) }
@@ -118,8 +134,11 @@ func newUintValue(val uint, p *uint) *uintValue {
 
 func (i *uintValue) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
 	*i = uintValue(v)
-	return err
+	return nil
 }
 
 func (i *uintValue) Get() interface{} { return uint(*i) }
@@ -136,8 +155,11 @@ func newUint64Value(val uint64, p *uint64) *uint64Value {
 
 func (i *uint64Value) Set(s string) error {
 	v, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return err
+	}
 	*i = uint64Value(v)
-	return err
+	return nil
 }
 
 func (i *uint64Value) Get() interface{} { return uint64(*i) }
@@ -171,8 +193,11 @@ func newFloat64Value(val float64, p *float64) *float64Value {
 
 func (f *float64Value) Set(s string) error {
 	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
 	*f = float64Value(v)
-	return err
+	return nil
 }
 
 func (f *float64Value) Get() interface{} { return float64(*f) }
@@ -189,18 +214,84 @@ func newDurationValue(val time.Duration, p *time.Duration) *durationValue {
 
 func (d *durationValue) Set(s string) error {
 	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
 	*d = durationValue(v)
-	return err
+	return nil
 }
 
 func (d *durationValue) Get() interface{} { return time.Duration(*d) }
 
 func (d *durationValue) String() string { return (*time.Duration)(d).String() }
 
+// -- encoding.TextUnmarshaler Value
+type textValue struct{ p encoding.TextUnmarshaler }
+
+func newTextValue(val encoding.TextMarshaler, p encoding.TextUnmarshaler) *textValue {
+	ptrVal := reflect.ValueOf(p)
+	if ptrVal.Kind() != reflect.Ptr {
+		panic("variable value type must be a pointer")
+	}
+	defVal := reflect.ValueOf(val)
+	if defVal.Kind() == reflect.Ptr {
+		defVal = defVal.Elem()
+	}
+	if defVal.Type() != ptrVal.Type().Elem() {
+		panic(fmt.Sprintf("default type does not match variable type: %v != %v", defVal.Type(), ptrVal.Type().Elem()))
+	}
+	ptrVal.Elem().Set(defVal)
+	return &textValue{p}
+}
+
+// Set unmarshals s into a new value so that the variable is left unchanged
+// if s is rejected.
+func (v *textValue) Set(s string) error {
+	ptrVal := reflect.ValueOf(v.p)
+	fresh := reflect.New(ptrVal.Type().Elem())
+	if err := fresh.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+		return err
+	}
+	ptrVal.Elem().Set(fresh.Elem())
+	return nil
+}
+
+// Get returns a pointer to a copy of the variable.
+func (v *textValue) Get() interface{} {
+	ptrVal := reflect.ValueOf(v.p)
+	c := reflect.New(ptrVal.Type().Elem())
+	c.Elem().Set(ptrVal.Elem())
+	return c.Interface()
+}
+
+func (v *textValue) save() func() {
+	ptrVal := reflect.ValueOf(v.p)
+	old := reflect.New(ptrVal.Type().Elem()).Elem()
+	old.Set(ptrVal.Elem())
+	return func() { ptrVal.Elem().Set(old) }
+}
+
+func (v *textValue) String() string {
+	if v == nil || v.p == nil {
+		return ""
+	}
+	if m, ok := v.p.(encoding.TextMarshaler); ok {
+		if b, err := m.MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+func (v *textValue) typeName() string {
+	return reflect.TypeOf(v.p).Elem().String()
+}
+
 // Value is the interface to the dynamic value stored in a EnvVar.
 // (The default value is represented as a string.)
 //
-// Set is called once for each EnvVar present.
+// Set is called once for each EnvVar present. Set should leave the value
+// unchanged if it returns an error.
 // The envvar package may call the String method with a zero-valued receiver,
 // such as a nil pointer.
 type Value interface {
@@ -217,6 +308,29 @@ type Getter interface {
 	Get() interface{}
 }
 
+// A saver is a Value that can record its current value, returning a
+// function that restores it.
+type saver interface {
+	save() (restore func())
+}
+
+// saveValue records the current value of value, returning a function that
+// restores it. Values that are not savers are saved by copying the variable
+// their pointer receivers point to, which suffices for Values, such as the
+// basic types of this package, that replace rather than modify their state.
+func saveValue(value Value) (restore func()) {
+	if s, ok := value.(saver); ok {
+		return s.save()
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return func() {}
+	}
+	old := reflect.New(rv.Elem().Type()).Elem()
+	old.Set(rv.Elem())
+	return func() { rv.Elem().Set(old) }
+}
+
 // ErrorHandling defines how EnvVarSet.Parse behaves if the parse fails.
 type ErrorHandling int
 
@@ -229,19 +343,52 @@ const (
 
 // A EnvVarSet represents a set of defined envVars. The zero value of a EnvVarSet
 // has no name and has ContinueOnError error handling.
+//
+// The methods of an EnvVarSet may be called concurrently. The variables
+// bound to its EnvVars and the Values of the EnvVars returned by Lookup and
+// passed to Visit and VisitAll are however written without
+// synchronization by Parse, Set and Reparse, and must not be read while the
+// set may be modified: use Get or a Snapshot instead.
 type EnvVarSet struct {
+	// Usage is the function called to print a description of the set's
+	// EnvVars. The field is a function (not a method) that may be changed
+	// to point to a custom function.
+	Usage func()
+
+	mu            sync.RWMutex // guards the fields below and the EnvVars
 	name          string
 	parsed        bool
 	actual        map[string]*EnvVar
 	formal        map[string]*EnvVar
+	aliases       map[string]*EnvVar
 	errorHandling ErrorHandling
-	output        io.Writer // nil means stderr; use out() accessor
+	collectErrors bool         // report every failure, not just the first
+	prefix        string       // prepended to the name of each EnvVar
+	separator     string       // between list elements; use Separator() accessor
+	fileSuffix    string       // marks env vars naming a file holding the value
+	expand        bool         // expand references to env vars in values
+	strict        string       // prefix under which unknown env vars are errors
+	subsets       []*EnvVarSet // sets returned by Subset
+	output        io.Writer    // nil means stderr; use out() accessor
 }
 
 // A EnvVar represents the state of a EnvVar.
 type EnvVar struct {
-	Name  string // name of environment variable
-	Value Value  // value as set
+	Name     string   // name of environment variable
+	Aliases  []string // other names of the variable, in decreasing precedence
+	Usage    string   // description of the variable
+	Value    Value    // value as set
+	DefValue string   // default value (as text); for usage message
+	Required bool     // whether Parse fails if the variable is not set
+	UsedName string   // name that supplied the value, if set
+	Secret   bool     // whether the value is masked in output; see Redact
+	Origin   Origin   // where the value came from
+
+	Validators []Validator // checks run on each value set
+
+	deprecated map[string]bool         // aliases that draw a warning when used
+	reset      func()                  // returns the value to its default
+	onChange   []func(old, new string) // called by Reparse on a change
 }
 
 // sortEnvVars returns the EnvVars as a slice in lexicographical sorted order.
@@ -260,6 +407,8 @@ func sortEnvVars(envVars map[string]*EnvVar) []*EnvVar {
 	return result
 }
 
+// out returns the destination for messages. It must be called with evs.mu
+// held.
 func (evs *EnvVarSet) out() io.Writer {
 	if evs.output == nil {
 		return os.Stderr
@@ -267,16 +416,256 @@ func (evs *EnvVarSet) out() io.Writer {
 	return evs.output
 }
 
-// SetOutput sets the destination for error messages.
+// Output returns the destination for usage and error messages. os.Stderr
+// is returned if output was not set or was set to nil.
+func (evs *EnvVarSet) Output() io.Writer {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	return evs.out()
+}
+
+// SetOutput sets the destination for usage and error messages.
 // If output is nil, os.Stderr is used.
 func (evs *EnvVarSet) SetOutput(output io.Writer) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
 	evs.output = output
 }
 
+// SetCollectErrors sets whether Parse carries on past a failure. When collect
+// is true, Parse processes the whole environment and reports every failure
+// together as an ErrorList, which is then handled according to the set's
+// ErrorHandling.
+func (evs *EnvVarSet) SetCollectErrors(collect bool) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	evs.collectErrors = collect
+}
+
+// typeName returns the name of the type of the value for use in usage
+// messages, or "value" if the type is not one provided by this package or
+// defined through VarOf or TextVar.
+func typeName(value Value) string {
+	switch v := value.(type) {
+	case *boolValue:
+		return "bool"
+	case *durationValue:
+		return "duration"
+	case *float32Value, *float64Value, *bigFloatValue:
+		return "float"
+	case *intValue, *int8Value, *int16Value, *int32Value, *int64Value, *bigIntValue:
+		return "int"
+	case *stringValue:
+		return "string"
+	case *uintValue, *uint8Value, *uint16Value, *uint32Value, *uint64Value:
+		return "uint"
+	case *complex128Value:
+		return "complex"
+	case *stringSliceValue:
+		return "[]string"
+	case *intSliceValue:
+		return "[]int"
+	case *float64SliceValue:
+		return "[]float"
+	case *durationSliceValue:
+		return "[]duration"
+	case *stringToStringValue:
+		return "map[string]string"
+	case *stringToIntValue:
+		return "map[string]int"
+	case *urlValue:
+		return "url"
+	case *ipValue:
+		return "ip"
+	case *ipNetValue:
+		return "cidr"
+	case *hostPortValue:
+		return "host:port"
+	case *addrPortValue:
+		return "addr:port"
+	case *byteSizeValue:
+		return "size"
+	case *timeValue:
+		return "time"
+	case *percentValue:
+		return "percent"
+	case *Rate:
+		return "rate"
+	case *Secret:
+		return "secret"
+	case interface{ typeName() string }:
+		return v.typeName()
+	}
+	return "value"
+}
+
+// PrintDefaults prints, to standard error unless configured otherwise, a
+// table of all defined EnvVars in the set giving the name, type, default
+// value, whether the variable is required and its usage.
+func (evs *EnvVarSet) PrintDefaults() {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "  NAME\tTYPE\tDEFAULT\tREQUIRED\tDESCRIPTION")
+	for _, envVar := range sortEnvVars(evs.formal) {
+		def := envVar.DefValue
+		if _, ok := envVar.Value.(*stringValue); ok && !envVar.Secret {
+			def = strconv.Quote(def)
+		}
+		def = envVar.maskedValue(def)
+		required := ""
+		if envVar.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", envVar.Name, typeName(envVar.Value), def, required, envVar.Usage)
+	}
+	w.Flush()
+	// Padding of empty trailing cells would leave trailing blanks.
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		if line != "" {
+			fmt.Fprintln(evs.out(), strings.TrimRight(line, " \n"))
+		}
+	}
+}
+
+// PrintDefaults prints, to standard error unless configured otherwise,
+// a table of all defined EnvVars in the default set.
+func PrintDefaults() {
+	EnvVars.PrintDefaults()
+}
+
+// defaultUsage is the default function to print a usage message.
+func (evs *EnvVarSet) defaultUsage() {
+	evs.mu.RLock()
+	if evs.name == "" {
+		fmt.Fprintf(evs.out(), "Environment variables:\n")
+	} else {
+		fmt.Fprintf(evs.out(), "Environment variables of %s:\n", evs.name)
+	}
+	evs.mu.RUnlock()
+	evs.PrintDefaults()
+}
+
+// Usage prints a usage message documenting all defined EnvVars to
+// EnvVars's output, which by default is os.Stderr. It may be called from a
+// program's own usage function, for instance when handling -help.
+// The function is a variable that may be changed to point to a custom
+// function.
+var Usage = func() {
+	fmt.Fprintf(EnvVars.Output(), "Environment variables of %s:\n", os.Args[0])
+	PrintDefaults()
+}
+
+// SetUsage sets the description of the named EnvVar, which must already be
+// defined, for use in usage messages.
+func (evs *EnvVarSet) SetUsage(name, usage string) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	evs.lookupDefined(name).Usage = usage
+}
+
+// SetUsage sets the description of the named EnvVar in the default set.
+func SetUsage(name, usage string) {
+	EnvVars.SetUsage(name, usage)
+}
+
+// Prefix returns the prefix prepended to the names of the set's EnvVars.
+func (evs *EnvVarSet) Prefix() string {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	return evs.prefix
+}
+
+// SetPrefix sets the prefix prepended to the names of the set's EnvVars.
+// Names passed to the set's methods omit the prefix, while the Name of each
+// EnvVar, and so Visit, Parse and error messages, include it. EnvVars
+// already defined are renamed to use the new prefix.
+func (evs *EnvVarSet) SetPrefix(prefix string) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	rename := func(name string) string {
+		return prefix + strings.TrimPrefix(name, evs.prefix)
+	}
+	formal := make(map[string]*EnvVar, len(evs.formal))
+	aliases := make(map[string]*EnvVar, len(evs.aliases))
+	for _, envVar := range evs.formal {
+		envVar.Name = rename(envVar.Name)
+		formal[envVar.Name] = envVar
+		deprecated := make(map[string]bool, len(envVar.deprecated))
+		for i, alias := range envVar.Aliases {
+			envVar.Aliases[i] = rename(alias)
+			aliases[envVar.Aliases[i]] = envVar
+			deprecated[envVar.Aliases[i]] = envVar.deprecated[alias]
+		}
+		envVar.deprecated = deprecated
+		if envVar.UsedName != "" {
+			envVar.UsedName = rename(envVar.UsedName)
+		}
+	}
+	if evs.actual != nil {
+		actual := make(map[string]*EnvVar, len(evs.actual))
+		for _, envVar := range evs.actual {
+			actual[envVar.Name] = envVar
+		}
+		evs.actual = actual
+	}
+	if evs.formal != nil {
+		evs.formal = formal
+	}
+	if evs.aliases != nil {
+		evs.aliases = aliases
+	}
+	evs.prefix = prefix
+}
+
+// SetFileSuffix sets the suffix, conventionally "_FILE", that marks an env
+// var as naming a file from which to read the value of the EnvVar named by
+// the rest of its name. For instance, with the suffix "_FILE" the value of
+// DB_PASSWORD may be given by DB_PASSWORD_FILE=/run/secrets/db, as is usual
+// for Docker and Kubernetes secrets. A trailing newline is removed from the
+// file's contents. It is an error for both forms to be set. An empty suffix,
+// the default, disables reading values from files.
+func (evs *EnvVarSet) SetFileSuffix(suffix string) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	evs.fileSuffix = suffix
+}
+
+// Subset returns a new, empty env var set whose prefix is the set's prefix
+// followed by prefix, so that nested components compose their prefixes.
+// The new set has the same name, error handling, output and parsing options
+// as the set but is parsed independently. If the set is strict, the subset
+// is strict only under its own prefix, and the set's strict check accepts
+// the env vars of the subset.
+func (evs *EnvVarSet) Subset(prefix string) *EnvVarSet {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	sub := NewEnvVarSet(evs.name, evs.errorHandling)
+	sub.collectErrors = evs.collectErrors
+	sub.output = evs.output
+	sub.prefix = evs.prefix + prefix
+	sub.separator = evs.separator
+	sub.fileSuffix = evs.fileSuffix
+	sub.expand = evs.expand
+	if evs.strict != "" {
+		switch {
+		case strings.HasPrefix(sub.prefix, evs.strict):
+			sub.strict = sub.prefix
+		case strings.HasPrefix(evs.strict, sub.prefix):
+			sub.strict = evs.strict
+		}
+	}
+	evs.subsets = append(evs.subsets, sub)
+	return sub
+}
+
 // VisitAll visits the sets EnvVars in lexicographical order, calling
-// fn for each. It visits all EnvVars, even those not set.
+// fn for each. It visits all EnvVars, even those not set. Each call is
+// passed a copy of the EnvVar, whose fields may be read while the set is
+// modified; its Value, however, is shared with the set.
 func (evs *EnvVarSet) VisitAll(fn func(*EnvVar)) {
-	for _, envVar := range sortEnvVars(evs.formal) {
+	for _, envVar := range evs.copyEnvVars(false) {
 		fn(envVar)
 	}
 }
@@ -288,13 +677,41 @@ func VisitAll(fn func(*EnvVar)) {
 }
 
 // Visit visits the sets EnvVars in lexicographical order, calling fn for each.
-// It visits only those EnvVars that have been set.
+// It visits only those EnvVars that have been set. Like VisitAll, it passes
+// copies of the EnvVars.
 func (evs *EnvVarSet) Visit(fn func(*EnvVar)) {
-	for _, envVar := range sortEnvVars(evs.actual) {
+	for _, envVar := range evs.copyEnvVars(true) {
 		fn(envVar)
 	}
 }
 
+// copyEnvVars returns, sorted by name, copies of the set's EnvVars, or of
+// only those that have been set if actual is true, taken under the set's
+// lock.
+func (evs *EnvVarSet) copyEnvVars(actual bool) []*EnvVar {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	envVars := evs.formal
+	if actual {
+		envVars = evs.actual
+	}
+	sorted := sortEnvVars(envVars)
+	for i, envVar := range sorted {
+		sorted[i] = envVar.copy()
+	}
+	return sorted
+}
+
+// copy returns a copy of envVar that shares none of its slices, so that
+// the copy may be read while the set modifies envVar. It must be called
+// with the set's lock held.
+func (envVar *EnvVar) copy() *EnvVar {
+	c := *envVar
+	c.Aliases = append([]string(nil), envVar.Aliases...)
+	c.Validators = append([]Validator(nil), envVar.Validators...)
+	return &c
+}
+
 // Visit visits the default sets EnvVars in lexicographical order,
 // calling fn for each. It visits only those EnvVars that have been set.
 func Visit(fn func(*EnvVar)) {
@@ -302,31 +719,88 @@ func Visit(fn func(*EnvVar)) {
 }
 
 // Lookup returns the EnvVar structure of the named EnvVar,
-// returning nil if none exists.
+// returning nil if none exists. The name, which may be an alias, is given
+// without the set's prefix, as it was when the EnvVar was defined.
+// Like Visit, Lookup returns a copy of the EnvVar, whose fields may be read
+// while the set is modified; its Value, however, is shared with the set.
 func (evs *EnvVarSet) Lookup(name string) *EnvVar {
-	return evs.formal[name]
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	if envVar := evs.resolve(evs.prefix + name); envVar != nil {
+		return envVar.copy()
+	}
+	return nil
 }
 
 // Lookup returns the EnvVar structure of the named EnvVar,
 // returning nil if none exists.
 func Lookup(name string) *EnvVar {
-	return EnvVars.formal[name]
+	return EnvVars.Lookup(name)
+}
+
+// lookupDefined returns the named EnvVar, panicking if it has not been
+// defined. It backs the methods that annotate existing EnvVars, which, like
+// redefinition in Var, can only fail through a programming error.
+func (evs *EnvVarSet) lookupDefined(name string) *EnvVar {
+	name = evs.prefix + name
+	envVar := evs.resolve(name)
+	if envVar == nil {
+		evs.definitionPanic("EnvVar not defined: " + name)
+	}
+	return envVar
+}
+
+// definitionPanic prints msg, qualified by the set's name, and panics with
+// it. It reports mistakes in the definition of EnvVars.
+func (evs *EnvVarSet) definitionPanic(msg string) {
+	if evs.name != "" {
+		msg = fmt.Sprintf("%s sets %s", evs.name, msg)
+	}
+	fmt.Fprintln(evs.out(), msg)
+	panic(msg)
+}
+
+// Required marks the named EnvVars as required: Parse fails if any of them
+// is not set. The EnvVars must already be defined.
+func (evs *EnvVarSet) Required(names ...string) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	for _, name := range names {
+		evs.lookupDefined(name).Required = true
+	}
+}
+
+// Required marks the named EnvVars in the default set as required.
+func Required(names ...string) {
+	EnvVars.Required(names...)
 }
 
-// Set sets the value of the named EnvVar.
+// Set sets the value of the named EnvVar. The name, which may be an alias,
+// is given without the set's prefix. Set returns an *UnknownError if there
+// is no such EnvVar, or a *ParseError if the value is rejected.
 func (evs *EnvVarSet) Set(name, value string) error {
-	envVar, ok := evs.formal[name]
-	if !ok {
-		return fmt.Errorf("no such environment variable %v", name)
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	name = evs.prefix + name
+	envVar := evs.resolve(name)
+	if envVar == nil {
+		return &UnknownError{Name: name}
 	}
+	restore := saveValue(envVar.Value)
 	err := envVar.Value.Set(value)
+	if err == nil {
+		err = validate(envVar)
+	}
 	if err != nil {
-		return err
+		restore()
+		return parseError(envVar, name, value, err, "", 0)
 	}
 	if evs.actual == nil {
 		evs.actual = make(map[string]*EnvVar)
 	}
-	evs.actual[name] = envVar
+	evs.actual[envVar.Name] = envVar
+	envVar.UsedName = name
+	envVar.setOrigin(Origin{Kind: OriginSet}, value)
 	return nil
 }
 
@@ -336,10 +810,14 @@ func Set(name, value string) error {
 }
 
 // NEnvVar returns the number of EnvVars that have been defined.
-func (evs *EnvVarSet) NEnvVar() int { return len(evs.actual) }
+func (evs *EnvVarSet) NEnvVar() int {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	return len(evs.actual)
+}
 
 // NEnvVar returns the number of EnvVars that have been defined.
-func NEnvVar() int { return len(EnvVars.actual) }
+func NEnvVar() int { return EnvVars.NEnvVar() }
 
 // BoolVar defines a bool EnvVar with specified name, and default value.
 // The argument p points to a bool variable in which to store the value of the EnvVar.
@@ -553,24 +1031,45 @@ func Duration(name string, value time.Duration) *time.Duration {
 	return EnvVars.Duration(name, value)
 }
 
+// TextVar defines an EnvVar with a specified name, and default value.
+// The argument p must be a pointer to a variable that will hold the value
+// of the EnvVar, and p must implement encoding.TextUnmarshaler.
+// If the EnvVar is used, the value will be passed to p's UnmarshalText method.
+// The type of the default value must be the same as the type of p.
+func (evs *EnvVarSet) TextVar(p encoding.TextUnmarshaler, name string, value encoding.TextMarshaler) {
+	evs.Var(newTextValue(value, p), name)
+}
+
+// TextVar defines an EnvVar with a specified name, and default value.
+// The argument p must be a pointer to a variable that will hold the value
+// of the EnvVar, and p must implement encoding.TextUnmarshaler.
+// If the EnvVar is used, the value will be passed to p's UnmarshalText method.
+// The type of the default value must be the same as the type of p.
+func TextVar(p encoding.TextUnmarshaler, name string, value encoding.TextMarshaler) {
+	EnvVars.Var(newTextValue(value, p), name)
+}
+
 // Var defines a EnvVar with the specified name. The type and value of the EnvVar
 // are represented by the first argument, of type Value, which typically holds a
 // user-defined implementation of Value. For instance, the caller could create a
 // EnvVar that turns a comma-separated string into a slice of strings by giving
 // the slice the methods of Value; in particular, Set would decompose the
 // comma-separated string into the slice.
+// The set's prefix, if any, is prepended to the name.
 func (evs *EnvVarSet) Var(value Value, name string) {
-	envVar := &EnvVar{name, value}
-	_, alreadythere := evs.formal[name]
-	if alreadythere {
-		var msg string
-		if evs.name == "" {
-			msg = fmt.Sprintf("EnvVar redefined: %s", name)
-		} else {
-			msg = fmt.Sprintf("%s sets EnvVar redefined: %s", evs.name, name)
-		}
-		fmt.Fprintln(evs.out(), msg)
-		panic(msg) // happens only if env vars are declared with identical names
+	evs.defineVar(value, name, false)
+}
+
+// defineVar defines an EnvVar as Var does, marking it secret if secret is
+// true before any other goroutine can see it.
+func (evs *EnvVarSet) defineVar(value Value, name string, secret bool) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	name = evs.prefix + name
+	envVar := &EnvVar{Name: name, Value: value, DefValue: value.String(), Secret: secret, reset: saveValue(value)}
+	if evs.resolve(name) != nil {
+		// happens only if env vars are declared with identical names
+		evs.definitionPanic("EnvVar redefined: " + name)
 	}
 	if evs.formal == nil {
 		evs.formal = make(map[string]*EnvVar)
@@ -590,58 +1089,253 @@ func Var(value Value, name string) {
 
 // failf prints to standard error a formatted error and returns the error.
 func (evs *EnvVarSet) failf(format string, a ...interface{}) error {
-	err := fmt.Errorf(format, a...)
+	return evs.fail(fmt.Errorf(format, a...))
+}
+
+// fail prints to standard error the error err and returns it.
+func (evs *EnvVarSet) fail(err error) error {
 	fmt.Fprintln(evs.out(), err)
 	return err
 }
 
-// parseOne parses one env var. It reports whether a env var was seen.
-func (evs *EnvVarSet) parseOne(envString string) error {
-	name := ""
-	value := ""
+// An entry is a single name=value definition from an environment.
+type entry struct {
+	name   string
+	value  string
+	kind   OriginKind // kind of the origin of the definition
+	source string     // name of the Source of the definition, if any
+	file   string     // dotenv file of the definition, if any
+	line   int        // line of the definition in a dotenv file, if any
+	tmpl   string     // value with its literal $ doubled, if quoted in a dotenv file
+}
+
+// template returns the value of e in the form expanded by SetExpand.
+func (e entry) template() string {
+	if e.tmpl != "" {
+		return e.tmpl
+	}
+	return e.value
+}
+
+// splitEntry splits envString, of the form name=value, into an entry.
+func splitEntry(envString string) entry {
 	for i := 1; i < len(envString); i++ { // equals cannot be first
 		if envString[i] == '=' {
-			value = envString[i+1:]
-			name = envString[0:i]
-			break
+			return entry{name: envString[0:i], value: envString[i+1:], kind: OriginEnviron}
+		}
+	}
+	return entry{}
+}
+
+// fileEnvVar returns the EnvVar whose value is read from the file named by
+// the env var name, and the name of the EnvVar used, or nil if name is not
+// such an env var.
+func (evs *EnvVarSet) fileEnvVar(name string) (*EnvVar, string) {
+	if evs.fileSuffix == "" || !strings.HasSuffix(name, evs.fileSuffix) {
+		return nil, ""
+	}
+	base := strings.TrimSuffix(name, evs.fileSuffix)
+	return evs.resolve(base), base
+}
+
+// readValueFile returns the contents of the named file without a trailing
+// newline.
+func readValueFile(filename string) (string, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// parseOne parses one env var. The environment being parsed is env, in
+// which later definitions of a name replace earlier ones. References that
+// env cannot resolve are looked up with outer, if it is not nil.
+func (evs *EnvVarSet) parseOne(e entry, env map[string]string, outer func(string) (string, bool)) error {
+	name, value := e.name, e.value
+	envVar, base := evs.resolve(name), name
+	origin := entryOrigin(e)
+	fromFile := false
+	if envVar == nil {
+		envVar, base = evs.fileEnvVar(name)
+		if envVar == nil && evs.isStrict(name) {
+			return evs.fail(&UnknownError{Name: name, Suggestion: evs.suggest(name), File: e.file, Line: e.line})
 		}
+		if envVar == nil { // skip this env var as we haven't defined it in the set
+			return nil
+		}
+		if _, ok := env[base]; ok {
+			return evs.fail(parseError(envVar, name, value, fmt.Errorf("%s is also set", base), e.file, e.line))
+		}
+		fromFile = true
 	}
-	envVar, alreadythere := evs.formal[name]
-	if !alreadythere { // skip this env var as we haven't defined it in the set
+	if evs.shadowed(envVar, base, env) {
 		return nil
 	}
-	if err := envVar.Value.Set(value); err != nil {
-		return evs.failf("invalid value %q for env var %s: %v", value, name, err)
+	if evs.expand {
+		x := &expander{env: env, outer: outer}
+		var err error
+		if value, err = x.expandVar(name, e.template()); err != nil {
+			return evs.fail(parseError(envVar, name, e.value, err, e.file, e.line))
+		}
+	}
+	if fromFile {
+		origin = Origin{Kind: OriginFile, Source: e.source, File: value}
+		var err error
+		if value, err = readValueFile(value); err != nil {
+			return evs.fail(parseError(envVar, name, e.value, err, e.file, e.line))
+		}
+	}
+	restore := saveValue(envVar.Value)
+	err := envVar.Value.Set(value)
+	if err == nil {
+		err = validate(envVar)
+	}
+	if err != nil {
+		restore()
+		return evs.fail(parseError(envVar, name, value, err, e.file, e.line))
+	}
+	if envVar.deprecated[base] {
+		fmt.Fprintf(evs.out(), "env var %s is deprecated, use %s instead\n", base, envVar.Name)
 	}
 	if evs.actual == nil {
 		evs.actual = make(map[string]*EnvVar)
 	}
-	evs.actual[name] = envVar
+	evs.actual[envVar.Name] = envVar
+	envVar.UsedName = name
+	envVar.setOrigin(origin, value)
 	return nil
 }
 
 // Parse parses all env var definitions. Must be called after all env vars in
 // the EnvVarSet are defined and before env vars are accessed by the program.
+// If the parse fails, the EnvVars are left with the values they had before.
+//
+// A rejected value is reported by a *ParseError, unset required EnvVars
+// by a *RequiredError and, if the set is strict, unknown env vars by an
+// *UnknownError. If the set collects errors, they are returned in an
+// ErrorList.
 func (evs *EnvVarSet) Parse(environment []string) error {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	return evs.parseEntries(splitEnvironment(environment), nil, true, false)
+}
+
+// splitEnvironment returns the entries of an environment in the form of
+// os.Environ.
+func splitEnvironment(environment []string) []entry {
+	entries := make([]entry, len(environment))
+	for i, envString := range environment {
+		entries[i] = splitEntry(envString)
+	}
+	return entries
+}
+
+// A setState records the state of a set's EnvVars so that a failed parse
+// can be undone.
+type setState struct {
+	actual map[string]*EnvVar
+	vars   []varState
+}
+
+type varState struct {
+	envVar   *EnvVar
+	usedName string
+	origin   Origin
+	restore  func()
+}
+
+// save records the state of the set's EnvVars.
+func (evs *EnvVarSet) save() *setState {
+	state := &setState{actual: make(map[string]*EnvVar, len(evs.actual))}
+	for name, envVar := range evs.actual {
+		state.actual[name] = envVar
+	}
+	for _, envVar := range evs.formal {
+		state.vars = append(state.vars, varState{envVar, envVar.UsedName, envVar.Origin, saveValue(envVar.Value)})
+	}
+	return state
+}
+
+// restore returns the set's EnvVars to the recorded state.
+func (evs *EnvVarSet) restore(state *setState) {
+	for _, v := range state.vars {
+		v.restore()
+		v.envVar.UsedName = v.usedName
+		v.envVar.Origin = v.origin
+	}
+	evs.actual = state.actual
+}
+
+// parseEntries parses the env vars of an environment given as entries,
+// expanding references to names it lacks, if expansion is on, with outer,
+// and checking that required EnvVars are set if required is true. If reset is
+// true, the EnvVars are first returned to their defaults and marked unset.
+// If the parse fails, the set's EnvVars are left as they were before it.
+func (evs *EnvVarSet) parseEntries(entries []entry, outer func(string) (string, bool), required, reset bool) error {
 	evs.parsed = true
-	for _, envString := range environment {
-		err := evs.parseOne(envString)
+	state := evs.save()
+	if reset {
+		evs.reset()
+	}
+	env := make(map[string]string, len(entries))
+	for _, e := range entries {
+		env[e.name] = e.template()
+	}
+	var errs ErrorList
+	for _, e := range entries {
+		err := evs.parseOne(e, env, outer)
 		if err != nil {
-			switch evs.errorHandling {
-			case ContinueOnError:
-				return err
-			case ExitOnError:
-				os.Exit(2)
-			case PanicOnError:
-				panic(err)
+			if !evs.collectErrors {
+				evs.restore(state)
+				return evs.handleError(err)
 			}
+			errs = append(errs, err)
+		}
+	}
+	if missing := evs.missingRequired(); required && len(missing) > 0 {
+		err := evs.fail(&RequiredError{Names: missing})
+		if !evs.collectErrors {
+			evs.restore(state)
+			return evs.handleError(err)
 		}
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		evs.restore(state)
+		return evs.handleError(errs)
 	}
 	return nil
 }
 
+// missingRequired returns, in lexicographical order, the names of the
+// required EnvVars that have not been set.
+func (evs *EnvVarSet) missingRequired() []string {
+	var missing []string
+	for _, envVar := range sortEnvVars(evs.formal) {
+		if envVar.Required && evs.actual[envVar.Name] == nil {
+			missing = append(missing, envVar.Name)
+		}
+	}
+	return missing
+}
+
+// handleError acts on a parse failure according to the set's ErrorHandling.
+func (evs *EnvVarSet) handleError(err error) error {
+	switch evs.errorHandling {
+	case ExitOnError:
+		os.Exit(2)
+	case PanicOnError:
+		panic(err)
+	}
+	return err
+}
+
 // Parsed reports whether evs.Parse has been called.
 func (evs *EnvVarSet) Parsed() bool {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
 	return evs.parsed
 }
 
@@ -661,6 +1355,18 @@ func Parsed() bool {
 // methods of EnvVars.
 var EnvVars = NewEnvVarSet(os.Args[0], ExitOnError)
 
+func init() {
+	// Override generic EnvVarSet default Usage with call to global Usage.
+	// Note: This is not EnvVars.Usage = Usage, because we want any eventual
+	// call to use any updated value of Usage, not the value it has when
+	// this is run.
+	EnvVars.Usage = commandLineUsage
+}
+
+func commandLineUsage() {
+	Usage()
+}
+
 // NewEnvVarSet returns a new, empty env var set with the specified name and
 // error handling property.
 func NewEnvVarSet(name string, errorHandling ErrorHandling) *EnvVarSet {
@@ -668,6 +1374,7 @@ func NewEnvVarSet(name string, errorHandling ErrorHandling) *EnvVarSet {
 		name:          name,
 		errorHandling: errorHandling,
 	}
+	evs.Usage = evs.defaultUsage
 	return evs
 }
 
@@ -675,6 +1382,8 @@ func NewEnvVarSet(name string, errorHandling ErrorHandling) *EnvVarSet {
 // By default, the zero EnvVarSet uses an empty name and the
 // ContinueOnError error handling policy.
 func (evs *EnvVarSet) Init(name string, errorHandling ErrorHandling) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
 	evs.name = name
 	evs.errorHandling = errorHandling
 }
@@ -7,9 +7,16 @@
 package envvar_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -275,3 +282,398 @@ func TestIntEnvVarOverflow(t *testing.T) {
 		t.Error("unexpected success setting Uint")
 	}
 }
+
+func TestParseStopsAtFirstError(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.Int("A", 0)
+	evs.Int("B", 0)
+	err := evs.Parse([]string{"A=x", "B=y"})
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("want *ParseError, got %T: %v", err, err)
+	}
+	if perr.Name != "A" || perr.Value != "x" {
+		t.Errorf("want error for A=x, got %s=%s", perr.Name, perr.Value)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var out bytes.Buffer
+	evs.SetOutput(&out)
+	evs.SetCollectErrors(true)
+	a := evs.Int("A", 0)
+	evs.Duration("B", 0)
+	c := evs.Bool("C", false)
+	err := evs.Parse([]string{"A=x", "B=y", "C=true", "D=z"})
+	var list ErrorList
+	if !errors.As(err, &list) {
+		t.Fatalf("want ErrorList, got %T: %v", err, err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("want 2 errors, got %d: %v", len(list), list)
+	}
+	for i, name := range []string{"A", "B"} {
+		var perr *ParseError
+		if !errors.As(list[i], &perr) || perr.Name != name {
+			t.Errorf("error %d: want *ParseError for %s, got %v", i, name, list[i])
+		}
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Error("errors.Is(err, strconv.ErrSyntax) = false")
+	}
+	if *a != 0 {
+		t.Errorf("A should be 0, is %d", *a)
+	}
+	if *c {
+		t.Error("C should be left unset by the failed Parse")
+	}
+	if n := strings.Count(out.String(), "\n"); n != 2 {
+		t.Errorf("want 2 lines of output, got %d: %q", n, out.String())
+	}
+}
+
+func TestRequired(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var out bytes.Buffer
+	evs.SetOutput(&out)
+	evs.String("DATABASE_URL", "")
+	evs.String("CACHE_URL", "")
+	evs.Int("PORT", 80)
+	evs.Required("DATABASE_URL", "CACHE_URL", "PORT")
+	err := evs.Parse([]string{"PORT=8080"})
+	if err == nil {
+		t.Fatal("want error for missing required env vars")
+	}
+	want := "required env vars not set: CACHE_URL, DATABASE_URL"
+	if err.Error() != want {
+		t.Errorf("want %q, got %q", want, err)
+	}
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("error not written to output: %q", out.String())
+	}
+	if err := evs.Parse([]string{"DATABASE_URL=a", "CACHE_URL=b", "PORT=8080"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRequiredCollected(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.SetCollectErrors(true)
+	evs.Int("PORT", 80)
+	evs.String("DATABASE_URL", "")
+	evs.Required("DATABASE_URL")
+	err := evs.Parse([]string{"PORT=x"})
+	var list ErrorList
+	if !errors.As(err, &list) || len(list) != 2 {
+		t.Fatalf("want ErrorList of 2 errors, got %v", err)
+	}
+}
+
+func TestRequiredUndefinedPanics(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	defer func() {
+		if recover() == nil {
+			t.Error("Required on an undefined env var did not panic")
+		}
+	}()
+	evs.Required("UNDEFINED")
+}
+
+const defaultOutput = `  NAME          TYPE      DEFAULT  REQUIRED  DESCRIPTION
+  DATABASE_URL  string    ""       yes       database to connect to
+  DEBUG         bool      false
+  PORT          int       80                 port to listen on
+  TIMEOUT       duration  5s
+  UV            value     []                 user defined
+`
+
+func TestPrintDefaults(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var buf bytes.Buffer
+	evs.SetOutput(&buf)
+	evs.Int("PORT", 80)
+	evs.String("DATABASE_URL", "")
+	evs.Bool("DEBUG", false)
+	evs.Duration("TIMEOUT", 5*time.Second)
+	evs.Var(&userVar{}, "UV")
+	evs.SetUsage("PORT", "port to listen on")
+	evs.SetUsage("DATABASE_URL", "database to connect to")
+	evs.SetUsage("UV", "user defined")
+	evs.Required("DATABASE_URL")
+	evs.PrintDefaults()
+	if buf.String() != defaultOutput {
+		t.Errorf("got:\n%q\nwant:\n%q", buf.String(), defaultOutput)
+	}
+	if def := evs.Lookup("PORT").DefValue; def != "80" {
+		t.Errorf("PORT DefValue should be 80, is %q", def)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var buf bytes.Buffer
+	evs.SetOutput(&buf)
+	evs.Int("PORT", 80)
+	evs.Usage()
+	if !strings.HasPrefix(buf.String(), "Environment variables of test:\n") {
+		t.Errorf("unexpected usage output %q", buf.String())
+	}
+	called := false
+	evs.Usage = func() { called = true }
+	evs.Usage()
+	if !called {
+		t.Error("custom Usage not called")
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	evs := NewEnvVarSet("billing", ContinueOnError)
+	var out bytes.Buffer
+	evs.SetOutput(&out)
+	evs.SetPrefix("BILLING_")
+	port := evs.Int("PORT", 80)
+	if err := evs.Parse([]string{"PORT=1", "BILLING_PORT=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 8080 {
+		t.Errorf("port should be 8080, is %d", *port)
+	}
+	ev := evs.Lookup("PORT")
+	if ev == nil || ev.Name != "BILLING_PORT" {
+		t.Fatalf("Lookup(PORT) = %v, want BILLING_PORT", ev)
+	}
+	var names []string
+	evs.Visit(func(ev *EnvVar) { names = append(names, ev.Name) })
+	if len(names) != 1 || names[0] != "BILLING_PORT" {
+		t.Errorf("Visit saw %v, want [BILLING_PORT]", names)
+	}
+	if err := evs.Set("PORT", "81"); err != nil || *port != 81 {
+		t.Errorf("Set(PORT, 81) = %v, port %d", err, *port)
+	}
+	err := evs.Parse([]string{"BILLING_PORT=x"})
+	if err == nil || !strings.Contains(err.Error(), "BILLING_PORT") {
+		t.Errorf("error should name BILLING_PORT, got %v", err)
+	}
+}
+
+func TestSetPrefixRenames(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	port := evs.Int("PORT", 80)
+	evs.Set("PORT", "81")
+	evs.SetPrefix("APP_")
+	if ev := evs.Lookup("PORT"); ev == nil || ev.Name != "APP_PORT" {
+		t.Fatalf("Lookup(PORT) = %v, want APP_PORT", ev)
+	}
+	if evs.NEnvVar() != 1 {
+		t.Error("set env var lost when prefix changed")
+	}
+	if err := evs.Parse([]string{"APP_PORT=82"}); err != nil || *port != 82 {
+		t.Errorf("Parse(APP_PORT=82) = %v, port %d", err, *port)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetPrefix("BILLING_")
+	db := evs.Subset("DB_")
+	if db.Prefix() != "BILLING_DB_" {
+		t.Errorf("subset prefix should be BILLING_DB_, is %q", db.Prefix())
+	}
+	url := db.String("URL", "")
+	if err := db.Parse([]string{"BILLING_DB_URL=postgres://db"}); err != nil {
+		t.Fatal(err)
+	}
+	if *url != "postgres://db" {
+		t.Errorf("url should be postgres://db, is %q", *url)
+	}
+	if evs.Lookup("DB_URL") != nil {
+		t.Error("subset env var defined in parent set")
+	}
+}
+
+func TestFileSuffix(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "db")
+	if err := ioutil.WriteFile(secret, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.SetFileSuffix("_FILE")
+	password := evs.String("DB_PASSWORD", "")
+	port := evs.Int("PORT", 80)
+	if err := evs.Parse([]string{"DB_PASSWORD_FILE=" + secret, "OTHER_FILE=/nonexistent"}); err != nil {
+		t.Fatal(err)
+	}
+	if *password != "s3cret" {
+		t.Errorf("password should be s3cret, is %q", *password)
+	}
+	if ev := evs.Lookup("DB_PASSWORD"); evs.NEnvVar() != 1 || ev == nil {
+		t.Error("DB_PASSWORD not recorded as set")
+	}
+
+	err := evs.Parse([]string{"DB_PASSWORD=x", "DB_PASSWORD_FILE=" + secret})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "DB_PASSWORD_FILE" {
+		t.Errorf("want *ParseError for DB_PASSWORD_FILE when both are set, got %v", err)
+	}
+
+	err = evs.Parse([]string{"PORT_FILE=" + filepath.Join(dir, "missing")})
+	if !errors.As(err, &perr) || !os.IsNotExist(errors.Unwrap(perr)) {
+		t.Errorf("want not exist error for PORT_FILE, got %v", err)
+	}
+
+	if err := ioutil.WriteFile(secret, []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := evs.Parse([]string{"PORT_FILE=" + secret}); err == nil {
+		t.Errorf("want error for invalid PORT_FILE contents, port %d", *port)
+	}
+}
+
+func TestFileSuffixDisabled(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	password := evs.String("DB_PASSWORD", "")
+	if err := evs.Parse([]string{"DB_PASSWORD_FILE=/nonexistent"}); err != nil {
+		t.Fatal(err)
+	}
+	if *password != "" {
+		t.Errorf("password should be empty, is %q", *password)
+	}
+}
+
+func TestSetLeavesValueOnError(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.Bool("BOOL", true)
+	evs.Int("INT", 8080)
+	evs.Int64("INT64", -2)
+	evs.Uint("UINT", 3)
+	evs.Uint64("UINT64", 4)
+	evs.String("STRING", "five")
+	evs.Float64("FLOAT64", 6.5)
+	evs.Duration("DURATION", 7*time.Second)
+	evs.StringSlice("STRINGS", []string{"a", "b"})
+	evs.IntSlice("INTS", []int{1, 2})
+	evs.Float64Slice("FLOATS", []float64{1.5})
+	evs.DurationSlice("DURATIONS", []time.Duration{time.Second})
+	evs.StringToString("STRINGMAP", map[string]string{"a": "b"})
+	evs.StringToInt("INTMAP", map[string]int{"a": 1})
+	evs.Validate("STRING", OneOf("five"))
+	invalid := map[string]string{
+		"BOOL":      "maybe",
+		"INT":       "abc",
+		"INT64":     "1.5",
+		"UINT":      "-1",
+		"UINT64":    "x",
+		"STRING":    "six",
+		"FLOAT64":   "y",
+		"DURATION":  "7",
+		"STRINGS":   `"unterminated`,
+		"INTS":      "1,x",
+		"FLOATS":    "1,y",
+		"DURATIONS": "1s,2",
+		"STRINGMAP": "a=1,a=2",
+		"INTMAP":    "a=x",
+	}
+	before := make(map[string]string)
+	evs.VisitAll(func(ev *EnvVar) { before[ev.Name] = ev.Value.String() })
+	if len(before) != len(invalid) {
+		t.Fatalf("test covers %d types, %d defined", len(invalid), len(before))
+	}
+	for name, value := range invalid {
+		if err := evs.Set(name, value); err == nil {
+			t.Errorf("Set(%s, %q) succeeded", name, value)
+		}
+		if err := evs.Parse([]string{name + "=" + value}); err == nil {
+			t.Errorf("Parse(%s=%s) succeeded", name, value)
+		}
+		if got := evs.Lookup(name).Value.String(); got != before[name] {
+			t.Errorf("%s changed from %q to %q by invalid value %q", name, before[name], got, value)
+		}
+	}
+	if evs.NEnvVar() != 0 {
+		t.Errorf("%d env vars recorded as set by failed parses", evs.NEnvVar())
+	}
+}
+
+func TestFailedParseRestoresAll(t *testing.T) {
+	for _, collect := range []bool{false, true} {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		evs.SetCollectErrors(collect)
+		port := evs.Int("PORT", 80)
+		hosts := evs.StringSlice("HOSTS", []string{"a"})
+		var uv userVar
+		evs.Var(&uv, "UV")
+		if err := evs.Set("PORT", "81"); err != nil {
+			t.Fatal(err)
+		}
+		err := evs.Parse([]string{"PORT=8080", "HOSTS=b,c", "UV=1", "DEBUG=x", "TIMEOUT=y"})
+		if err != nil {
+			t.Fatalf("collect %v: %v", collect, err)
+		}
+		evs.Bool("DEBUG", false)
+		evs.Parse([]string{"PORT=9090", "HOSTS=d", "UV=2", "DEBUG=x"})
+		if *port != 8080 || len(*hosts) != 2 || len(uv) != 1 {
+			t.Errorf("collect %v: failed Parse changed values: port %d, hosts %v, uv %v", collect, *port, *hosts, uv)
+		}
+		if ev := evs.Lookup("DEBUG"); evs.NEnvVar() != 3 || ev.UsedName != "" {
+			t.Errorf("collect %v: failed Parse changed set env vars", collect)
+		}
+	}
+}
+
+func TestTextVar(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	var ip net.IP
+	evs.TextVar(&ip, "IP", net.IPv4(127, 0, 0, 1))
+	if got := evs.Lookup("IP").DefValue; got != "127.0.0.1" {
+		t.Errorf("DefValue: got %q, want 127.0.0.1", got)
+	}
+	if err := evs.Parse([]string{"IP=10.1.2.3"}); err != nil {
+		t.Fatal(err)
+	}
+	if !ip.Equal(net.IPv4(10, 1, 2, 3)) {
+		t.Errorf("got %v, want 10.1.2.3", ip)
+	}
+	if err := evs.Parse([]string{"IP=10.1.2"}); err == nil {
+		t.Error("expected error for invalid IP")
+	}
+	if !ip.Equal(net.IPv4(10, 1, 2, 3)) {
+		t.Errorf("failed parse changed value to %v", ip)
+	}
+	var buf bytes.Buffer
+	evs.SetOutput(&buf)
+	evs.PrintDefaults()
+	if want := "IP    net.IP  127.0.0.1"; !strings.Contains(buf.String(), want) {
+		t.Errorf("PrintDefaults output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestTextVarMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for mismatched default type")
+		}
+	}()
+	var ip net.IP
+	NewEnvVarSet("test", ContinueOnError).TextVar(&ip, "IP", time.Time{})
+}
+
+func TestOutput(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	if evs.Output() != os.Stderr {
+		t.Errorf("default output is not os.Stderr")
+	}
+	var buf bytes.Buffer
+	evs.SetOutput(&buf)
+	if evs.Output() != &buf {
+		t.Errorf("Output does not return the output set")
+	}
+}
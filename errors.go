@@ -0,0 +1,107 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// These errors are matched, using errors.Is, by the errors of the
+// corresponding types returned by Parse and Set.
+var (
+	// ErrUnknown is matched by an UnknownError.
+	ErrUnknown = errors.New("unknown env var")
+	// ErrRequired is matched by a RequiredError.
+	ErrRequired = errors.New("required env var not set")
+)
+
+// A ParseError records a value that could not be stored in an EnvVar.
+type ParseError struct {
+	Name  string // name of the environment variable
+	Value string // offending value
+	Err   error  // reason the value was rejected, typically from strconv
+	File  string // dotenv file of the definition, if any
+	Line  int    // line of the definition in a dotenv file, if any
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("invalid value %q for env var %s: %v", e.Value, e.Name, e.Err)
+	if e.Line > 0 {
+		msg = location(e.File, e.Line) + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// An UnknownError records an attempt to set an env var that is not defined.
+type UnknownError struct {
+	Name       string // name of the environment variable
+	Suggestion string // name of a defined env var close to Name, if any
+	File       string // dotenv file of the definition, if any
+	Line       int    // line of the definition in a dotenv file, if any
+}
+
+func (e *UnknownError) Error() string {
+	msg := fmt.Sprintf("no such environment variable %v", e.Name)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf("; did you mean %s?", e.Suggestion)
+	}
+	if e.Line > 0 {
+		msg = location(e.File, e.Line) + ": " + msg
+	}
+	return msg
+}
+
+// Is reports whether target is ErrUnknown.
+func (e *UnknownError) Is(target error) bool { return target == ErrUnknown }
+
+// A RequiredError records the required env vars not set by a parse.
+type RequiredError struct {
+	Names []string // names of the environment variables
+}
+
+func (e *RequiredError) Error() string {
+	return "required env vars not set: " + strings.Join(e.Names, ", ")
+}
+
+// Is reports whether target is ErrRequired.
+func (e *RequiredError) Is(target error) bool { return target == ErrRequired }
+
+// A SyntaxError records a malformed line of a dotenv file.
+type SyntaxError struct {
+	File string // name of the file, if known
+	Line int    // line of the error
+	Msg  string // description of the error
+}
+
+func (e *SyntaxError) Error() string {
+	return location(e.File, e.Line) + ": " + e.Msg
+}
+
+// An ErrorList is a list of errors returned by EnvVarSet.Parse when the set
+// collects errors. See EnvVarSet.SetCollectErrors.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(l), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors in the list so that errors.Is and errors.As
+// inspect each of them.
+func (l ErrorList) Unwrap() []error { return l }
@@ -0,0 +1,83 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestParseErrorFromSet(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.Int("PORT", 80)
+	err := evs.Set("PORT", "abc")
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("want *ParseError, got %T: %v", err, err)
+	}
+	if perr.Name != "PORT" || perr.Value != "abc" || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("unexpected ParseError %+v", perr)
+	}
+	want := `invalid value "abc" for env var PORT: strconv.ParseInt: parsing "abc": invalid syntax`
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err, want)
+	}
+}
+
+func TestUnknownError(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	err := evs.Set("MISSING", "x")
+	var uerr *UnknownError
+	if !errors.As(err, &uerr) || uerr.Name != "MISSING" {
+		t.Fatalf("want *UnknownError for MISSING, got %v", err)
+	}
+	if !errors.Is(err, ErrUnknown) {
+		t.Error("errors.Is(err, ErrUnknown) = false")
+	}
+	if err.Error() != "no such environment variable MISSING" {
+		t.Errorf("unexpected message %q", err)
+	}
+}
+
+func TestRequiredError(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.SetCollectErrors(true)
+	evs.String("B", "")
+	evs.String("A", "")
+	evs.Int("PORT", 0)
+	evs.Required("A", "B")
+	err := evs.Parse([]string{"PORT=x"})
+	if !errors.Is(err, ErrRequired) {
+		t.Fatalf("errors.Is(err, ErrRequired) = false for %v", err)
+	}
+	var rerr *RequiredError
+	if !errors.As(err, &rerr) || !reflect.DeepEqual(rerr.Names, []string{"A", "B"}) {
+		t.Errorf("want *RequiredError for A, B, got %v", err)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "PORT" {
+		t.Errorf("want *ParseError for PORT, got %v", err)
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	err := evs.ParseReader(strings.NewReader("A=1\nB\n"))
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || serr.Line != 2 || serr.File != "" {
+		t.Fatalf("want *SyntaxError at line 2, got %v", err)
+	}
+	if err.Error() != "line 2: missing = after B" {
+		t.Errorf("unexpected message %q", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetExpand sets whether references to other env vars in values are
+// expanded before the values are stored. References take the forms
+//
+//	$NAME or ${NAME}      the value of NAME
+//	${NAME:-default}      the value of NAME, or default if NAME is unset or empty
+//	${NAME:?message}      the value of NAME, or an error with message if NAME is unset or empty
+//
+// and $$ stands for a literal $. Names are resolved against the environment
+// being parsed, with the values of referenced env vars expanded in turn;
+// ParseFile and ParseReader also resolve names the file does not define
+// against the process environment, whose values are used as they are. A
+// reference to an unset env var or a cycle of references is a parse error.
+// In dotenv files, single-quoted text and the escape \$ are not expanded.
+// Expansion is off by default.
+func (evs *EnvVarSet) SetExpand(expand bool) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	evs.expand = expand
+}
+
+// An expander expands references to env vars in values.
+type expander struct {
+	env   map[string]string
+	outer func(string) (string, bool) // looks up names env lacks; values are not expanded
+	stack []string                    // names of the env vars being expanded, innermost last
+}
+
+// expandVar returns the expanded value of the named env var, whose raw
+// value is value.
+func (x *expander) expandVar(name, value string) (string, error) {
+	for i, n := range x.stack {
+		if n == name {
+			cycle := append(append([]string(nil), x.stack[i:]...), name)
+			return "", fmt.Errorf("reference cycle %s", strings.Join(cycle, " -> "))
+		}
+	}
+	x.stack = append(x.stack, name)
+	defer func() { x.stack = x.stack[:len(x.stack)-1] }()
+	return x.expand(value)
+}
+
+// expand returns s with its references expanded.
+func (x *expander) expand(s string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i+1 == len(s) {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		s = s[i+1:]
+		switch {
+		case s[0] == '$':
+			b.WriteByte('$')
+			s = s[1:]
+		case s[0] == '{':
+			end := closingBrace(s)
+			if end < 0 {
+				return "", errors.New("unterminated ${")
+			}
+			v, err := x.braced(s[1:end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			s = s[end+1:]
+		case isNameStart(s[0]):
+			n := 1
+			for n < len(s) && isNameChar(s[n]) {
+				n++
+			}
+			v, err := x.lookup(s[:n])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			s = s[n:]
+		default:
+			b.WriteByte('$')
+		}
+	}
+}
+
+// braced returns the expansion of the reference ${ref}.
+func (x *expander) braced(ref string) (string, error) {
+	name, op, word := ref, byte(0), ""
+	if i := strings.IndexByte(ref, ':'); i >= 0 && i+1 < len(ref) {
+		name, op, word = ref[:i], ref[i+1], ref[i+2:]
+	}
+	if !isName(name) || op != 0 && op != '-' && op != '?' {
+		return "", fmt.Errorf("bad substitution ${%s}", ref)
+	}
+	if op == 0 {
+		return x.lookup(name)
+	}
+	if value, ok, err := x.get(name); err != nil || ok && value != "" {
+		return value, err
+	}
+	word, err := x.expand(word)
+	if err != nil {
+		return "", err
+	}
+	if op == '-' {
+		return word, nil
+	}
+	if word == "" {
+		word = "not set"
+	}
+	return "", fmt.Errorf("%s: %s", name, word)
+}
+
+// lookup returns the expanded value of the named env var.
+func (x *expander) lookup(name string) (string, error) {
+	value, ok, err := x.get(name)
+	if err == nil && !ok {
+		err = fmt.Errorf("undefined env var %s", name)
+	}
+	return value, err
+}
+
+// get returns the expanded value of the named env var, and whether it is
+// set.
+func (x *expander) get(name string) (string, bool, error) {
+	if value, ok := x.env[name]; ok {
+		value, err := x.expandVar(name, value)
+		return value, true, err
+	}
+	if x.outer != nil {
+		if value, ok := x.outer(name); ok {
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// closingBrace returns the index in s, which starts with a brace, of the
+// matching closing brace, or -1 if there is none.
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isName(s string) bool {
+	if s == "" || !isNameStart(s[0]) {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if !isNameChar(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9'
+}
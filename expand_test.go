@@ -0,0 +1,158 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestExpand(t *testing.T) {
+	env := []string{
+		"HOME=/home/gopher",
+		"HOST=example.com",
+		"PORT=8080",
+		"EMPTY=",
+		"BASE=${HOME}/base",
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{"$HOME/data", "/home/gopher/data"},
+		{"${HOME}/data", "/home/gopher/data"},
+		{"http://${HOST}:${PORT}/", "http://example.com:8080/"},
+		{"$HOST$PORT", "example.com8080"},
+		{"${BASE}/x", "/home/gopher/base/x"},
+		{"${MISSING:-fallback}", "fallback"},
+		{"${EMPTY:-fallback}", "fallback"},
+		{"${MISSING:-$HOST}", "example.com"},
+		{"${MISSING:-${HOST}:${PORT}}", "example.com:8080"},
+		{"${HOST:?needed}", "example.com"},
+		{"cost $$5", "cost $5"},
+		{"a $ b $", "a $ b $"},
+		{"$1", "$1"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetExpand(true)
+		v := evs.String("V", "")
+		if err := evs.Parse(append(env, "V="+test.in)); err != nil {
+			t.Errorf("V=%s: %v", test.in, err)
+			continue
+		}
+		if *v != test.want {
+			t.Errorf("V=%s expanded to %q, want %q", test.in, *v, test.want)
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		env  []string
+		want string
+	}{
+		{[]string{"V=$MISSING"}, "undefined env var MISSING"},
+		{[]string{"V=${MISSING"}, "unterminated ${"},
+		{[]string{"V=${MISSING:?must be set}"}, "MISSING: must be set"},
+		{[]string{"V=${EMPTY:?}", "EMPTY="}, "EMPTY: not set"},
+		{[]string{"V=${A B}"}, "bad substitution ${A B}"},
+		{[]string{"V=${A:+x}", "A=1"}, "bad substitution ${A:+x}"},
+		{[]string{"V=$A", "A=$B", "B=${A}"}, "reference cycle A -> B -> A"},
+		{[]string{"V=$V"}, "reference cycle V -> V"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		evs.SetExpand(true)
+		v := evs.String("V", "default")
+		err := evs.Parse(test.env)
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Name != "V" || !strings.HasSuffix(err.Error(), test.want) {
+			t.Errorf("Parse(%q): want *ParseError for V ending %q, got %v", test.env, test.want, err)
+		}
+		if *v != "default" {
+			t.Errorf("Parse(%q) set V to %q", test.env, *v)
+		}
+	}
+}
+
+func TestExpandDisabled(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	v := evs.String("V", "")
+	if err := evs.Parse([]string{"HOME=/home", "V=$HOME"}); err != nil {
+		t.Fatal(err)
+	}
+	if *v != "$HOME" {
+		t.Errorf("V = %q, want $HOME unexpanded", *v)
+	}
+}
+
+func TestExpandDotenv(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetExpand(true)
+	url := evs.String("URL", "")
+	if err := evs.ParseReader(strings.NewReader("HOST=db\nURL=postgres://${HOST}/app\n")); err != nil {
+		t.Fatal(err)
+	}
+	if *url != "postgres://db/app" {
+		t.Errorf("URL = %q", *url)
+	}
+}
+
+func TestExpandDotenvQuoting(t *testing.T) {
+	const src = `HOME=/h
+UNQUOTED=lit $HOME
+SINGLE='lit $HOME'
+DOUBLE="lit $HOME"
+ESCAPED="lit \$HOME and $HOME"
+REF=$SINGLE
+`
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetExpand(true)
+	tests := []struct {
+		name, want string
+		value      *string
+	}{
+		{"UNQUOTED", "lit /h", evs.String("UNQUOTED", "")},
+		{"SINGLE", "lit $HOME", evs.String("SINGLE", "")},
+		{"DOUBLE", "lit /h", evs.String("DOUBLE", "")},
+		{"ESCAPED", "lit $HOME and /h", evs.String("ESCAPED", "")},
+		{"REF", "lit $HOME", evs.String("REF", "")},
+	}
+	if err := evs.ParseReader(strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		if *test.value != test.want {
+			t.Errorf("%s = %q, want %q", test.name, *test.value, test.want)
+		}
+	}
+}
+
+func TestExpandDotenvEnviron(t *testing.T) {
+	t.Setenv("ENVVAR_TEST_HOME", "/home/gopher")
+	t.Setenv("ENVVAR_TEST_USER", "$literal")
+	t.Setenv("ENVVAR_TEST_SHADOWED", "environ")
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetExpand(true)
+	dir := evs.String("DATA_DIR", "")
+	user := evs.String("USER_NAME", "")
+	name := evs.String("NAME", "")
+	const src = "DATA_DIR=${ENVVAR_TEST_HOME}/data\nUSER_NAME=$ENVVAR_TEST_USER\nENVVAR_TEST_SHADOWED=file\nNAME=$ENVVAR_TEST_SHADOWED\n"
+	if err := evs.ParseReader(strings.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	if *dir != "/home/gopher/data" || *user != "$literal" || *name != "file" {
+		t.Errorf("got DATA_DIR=%q USER_NAME=%q NAME=%q", *dir, *user, *name)
+	}
+	if err := evs.Parse([]string{"DATA_DIR=${ENVVAR_TEST_HOME}/data"}); err == nil {
+		t.Error("Parse resolved a reference outside the environment being parsed")
+	}
+}
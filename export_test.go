@@ -14,4 +14,5 @@ import "os"
 // parse errors in envvar handling will not exit the program.
 func ResetForTesting() {
 	EnvVars = NewEnvVarSet(os.Args[0], ContinueOnError)
+	EnvVars.Usage = commandLineUsage
 }
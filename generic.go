@@ -0,0 +1,86 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// -- generic Value
+type genericValue[T any] struct {
+	p     *T
+	parse func(string) (T, error)
+}
+
+func newGenericValue[T any](val T, p *T, parse func(string) (T, error)) *genericValue[T] {
+	*p = val
+	return &genericValue[T]{p, parse}
+}
+
+func (v *genericValue[T]) Set(s string) error {
+	t, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	*v.p = t
+	return nil
+}
+
+func (v *genericValue[T]) Get() interface{} { return *v.p }
+
+func (v *genericValue[T]) save() func() {
+	old := *v.p
+	return func() { *v.p = old }
+}
+
+func (v *genericValue[T]) String() string {
+	if v == nil || v.p == nil {
+		return ""
+	}
+	return fmt.Sprint(*v.p)
+}
+
+func (v *genericValue[T]) typeName() string {
+	return reflect.TypeOf(v.p).Elem().String()
+}
+
+// VarOf defines an EnvVar of type T with specified name, and default value
+// in evs. The argument p points to a T variable in which to store the value
+// of the EnvVar. Values are converted by parse, which should return an error
+// if its argument is not a valid T, and formatted as by fmt.Sprint.
+func VarOf[T any](evs *EnvVarSet, p *T, name string, value T, parse func(string) (T, error)) {
+	evs.Var(newGenericValue(value, p, parse), name)
+}
+
+// Define defines an EnvVar of type T with specified name, and default value
+// in evs. The return value is the address of a T variable that stores the
+// value of the EnvVar. Values are converted by parse as for VarOf.
+func Define[T any](evs *EnvVarSet, name string, value T, parse func(string) (T, error)) *T {
+	p := new(T)
+	VarOf(evs, p, name, value, parse)
+	return p
+}
+
+// Get returns the value of the named EnvVar of evs as a T. The name, which
+// may be an alias, is given as for Lookup. The boolean is false if the
+// EnvVar is not defined, its Value does not satisfy Getter or it does not
+// hold a T. Unlike reading the variable bound to the EnvVar, Get may be
+// called while the set is being modified.
+func Get[T any](evs *EnvVarSet, name string) (T, bool) {
+	var zero T
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	envVar := evs.resolve(evs.prefix + name)
+	if envVar == nil {
+		return zero, false
+	}
+	g, ok := envVar.Value.(Getter)
+	if !ok {
+		return zero, false
+	}
+	t, ok := g.Get().(T)
+	return t, ok
+}
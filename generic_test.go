@@ -0,0 +1,90 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+type level int
+
+func (l level) String() string { return [...]string{"debug", "info", "warn"}[l] }
+
+func parseLevel(s string) (level, error) {
+	for l := level(0); l <= 2; l++ {
+		if l.String() == s {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown level %q", s)
+}
+
+func TestDefine(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	lvl := Define(evs, "LEVEL", level(1), parseLevel)
+	var other level
+	VarOf(evs, &other, "OTHER", 0, parseLevel)
+	if *lvl != 1 || evs.Lookup("LEVEL").DefValue != "info" {
+		t.Errorf("default: got %v, %q", *lvl, evs.Lookup("LEVEL").DefValue)
+	}
+	if err := evs.Parse([]string{"LEVEL=warn", "OTHER=debug"}); err != nil {
+		t.Fatal(err)
+	}
+	if *lvl != 2 || other != 0 {
+		t.Errorf("got %v, %v", *lvl, other)
+	}
+	if l, ok := Get[level](evs, "LEVEL"); !ok || l != 2 {
+		t.Errorf("Get[level]: got %v, %v", l, ok)
+	}
+	if _, ok := Get[int](evs, "LEVEL"); ok {
+		t.Error("Get[int] of a level succeeded")
+	}
+	if _, ok := Get[level](evs, "NONE"); ok {
+		t.Error("Get of an undefined EnvVar succeeded")
+	}
+
+	err := evs.Parse([]string{"LEVEL=debug", "OTHER=trace"})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "OTHER" {
+		t.Fatalf("got %v, want *ParseError for OTHER", err)
+	}
+	if *lvl != 2 {
+		t.Errorf("LEVEL changed by failed parse: %v", *lvl)
+	}
+}
+
+func TestGetBuiltin(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.Int("PORT", 80)
+	evs.StringSlice("HOSTS", []string{"a"})
+	if err := evs.Parse([]string{"PORT=8080", "HOSTS=b,c"}); err != nil {
+		t.Fatal(err)
+	}
+	if port, ok := Get[int](evs, "PORT"); !ok || port != 8080 {
+		t.Errorf("PORT: got %v, %v", port, ok)
+	}
+	if hosts, ok := Get[[]string](evs, "HOSTS"); !ok || strings.Join(hosts, " ") != "b c" {
+		t.Errorf("HOSTS: got %v, %v", hosts, ok)
+	}
+}
+
+func TestDefinePrintDefaults(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var buf bytes.Buffer
+	evs.SetOutput(&buf)
+	Define(evs, "LEVEL", level(0), parseLevel)
+	evs.PrintDefaults()
+	if want := "LEVEL  envvar_test.level  debug"; !strings.Contains(buf.String(), want) {
+		t.Errorf("PrintDefaults output missing %q:\n%s", want, buf.String())
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/dyson/envvar
+
+go 1.20
@@ -0,0 +1,200 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// splitMap splits s into key=value pairs separated by sep, applying the
+// quoting rules of splitList to each pair. Only the first equals sign of a
+// pair separates the key from the value. It is an error for a key to be
+// empty or repeated.
+func splitMap(s, sep string) (keys, values []string, err error) {
+	pairs, err := splitList(s, sep)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		i := strings.Index(pair, "=")
+		if i < 0 {
+			return nil, nil, fmt.Errorf("%q is not of the form key=value", pair)
+		}
+		key := pair[:i]
+		if key == "" {
+			return nil, nil, fmt.Errorf("empty key in %q", pair)
+		}
+		if seen[key] {
+			return nil, nil, fmt.Errorf("duplicate key %q", key)
+		}
+		seen[key] = true
+		keys = append(keys, key)
+		values = append(values, pair[i+1:])
+	}
+	return keys, values, nil
+}
+
+// joinMap joins the pairs of m, in sorted key order, such that splitMap
+// recovers them.
+func joinMap(m map[string]string, sep string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + m[k]
+	}
+	return joinList(pairs, sep)
+}
+
+// -- map[string]string Value
+type stringToStringValue struct {
+	p   *map[string]string
+	sep string
+}
+
+func newStringToStringValue(val map[string]string, p *map[string]string, sep string) *stringToStringValue {
+	*p = val
+	return &stringToStringValue{p, sep}
+}
+
+func (m *stringToStringValue) Set(s string) error {
+	keys, values, err := splitMap(s, m.sep)
+	if err != nil {
+		return err
+	}
+	v := make(map[string]string, len(keys))
+	for i, k := range keys {
+		v[k] = values[i]
+	}
+	*m.p = v
+	return nil
+}
+
+func (m *stringToStringValue) Get() interface{} { return *m.p }
+
+func (m *stringToStringValue) save() func() {
+	v := *m.p
+	return func() { *m.p = v }
+}
+
+func (m *stringToStringValue) String() string {
+	if m == nil || m.p == nil {
+		return ""
+	}
+	return joinMap(*m.p, m.sep)
+}
+
+// -- map[string]int Value
+type stringToIntValue struct {
+	p   *map[string]int
+	sep string
+}
+
+func newStringToIntValue(val map[string]int, p *map[string]int, sep string) *stringToIntValue {
+	*p = val
+	return &stringToIntValue{p, sep}
+}
+
+func (m *stringToIntValue) Set(s string) error {
+	keys, values, err := splitMap(s, m.sep)
+	if err != nil {
+		return err
+	}
+	v := make(map[string]int, len(keys))
+	for i, k := range keys {
+		n, err := strconv.ParseInt(values[i], 0, strconv.IntSize)
+		if err != nil {
+			return fmt.Errorf("key %q: %w", k, err)
+		}
+		v[k] = int(n)
+	}
+	*m.p = v
+	return nil
+}
+
+func (m *stringToIntValue) Get() interface{} { return *m.p }
+
+func (m *stringToIntValue) save() func() {
+	v := *m.p
+	return func() { *m.p = v }
+}
+
+func (m *stringToIntValue) String() string {
+	if m == nil || m.p == nil {
+		return ""
+	}
+	s := make(map[string]string, len(*m.p))
+	for k, v := range *m.p {
+		s[k] = strconv.Itoa(v)
+	}
+	return joinMap(s, m.sep)
+}
+
+// StringToStringVar defines a map[string]string EnvVar with specified name, and default value.
+// The argument p points to a map[string]string variable in which to store the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the set's separator.
+func (evs *EnvVarSet) StringToStringVar(p *map[string]string, name string, value map[string]string) {
+	evs.Var(newStringToStringValue(value, p, evs.Separator()), name)
+}
+
+// StringToStringVar defines a map[string]string EnvVar with specified name, and default value.
+// The argument p points to a map[string]string variable in which to store the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the default set's separator.
+func StringToStringVar(p *map[string]string, name string, value map[string]string) {
+	EnvVars.StringToStringVar(p, name, value)
+}
+
+// StringToString defines a map[string]string EnvVar with specified name, and default value.
+// The return value is the address of a map[string]string variable that stores the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the set's separator.
+func (evs *EnvVarSet) StringToString(name string, value map[string]string) *map[string]string {
+	p := new(map[string]string)
+	evs.StringToStringVar(p, name, value)
+	return p
+}
+
+// StringToString defines a map[string]string EnvVar with specified name, and default value.
+// The return value is the address of a map[string]string variable that stores the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the default set's separator.
+func StringToString(name string, value map[string]string) *map[string]string {
+	return EnvVars.StringToString(name, value)
+}
+
+// StringToIntVar defines a map[string]int EnvVar with specified name, and default value.
+// The argument p points to a map[string]int variable in which to store the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the set's separator.
+func (evs *EnvVarSet) StringToIntVar(p *map[string]int, name string, value map[string]int) {
+	evs.Var(newStringToIntValue(value, p, evs.Separator()), name)
+}
+
+// StringToIntVar defines a map[string]int EnvVar with specified name, and default value.
+// The argument p points to a map[string]int variable in which to store the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the default set's separator.
+func StringToIntVar(p *map[string]int, name string, value map[string]int) {
+	EnvVars.StringToIntVar(p, name, value)
+}
+
+// StringToInt defines a map[string]int EnvVar with specified name, and default value.
+// The return value is the address of a map[string]int variable that stores the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the set's separator.
+func (evs *EnvVarSet) StringToInt(name string, value map[string]int) *map[string]int {
+	p := new(map[string]int)
+	evs.StringToIntVar(p, name, value)
+	return p
+}
+
+// StringToInt defines a map[string]int EnvVar with specified name, and default value.
+// The return value is the address of a map[string]int variable that stores the value of the EnvVar.
+// The EnvVar accepts key=value pairs separated by the default set's separator.
+func StringToInt(name string, value map[string]int) *map[string]int {
+	return EnvVars.StringToInt(name, value)
+}
@@ -0,0 +1,72 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestStringToString(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	labels := evs.StringToString("LABELS", map[string]string{"a": "b"})
+	if err := evs.Parse([]string{`LABELS=tier=web,team=core,"url=http://x/?a=1,2"`}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"team": "core", "tier": "web", "url": "http://x/?a=1,2"}
+	if !reflect.DeepEqual(*labels, want) {
+		t.Errorf("labels = %v, want %v", *labels, want)
+	}
+	str := evs.Lookup("LABELS").Value.String()
+	if str != `team=core,tier=web,"url=http://x/?a=1,2"` {
+		t.Errorf("String() = %q, want sorted keys", str)
+	}
+	if err := evs.Set("LABELS", str); err != nil || !reflect.DeepEqual(*labels, want) {
+		t.Errorf("round trip via %q = %v, %v", str, *labels, err)
+	}
+}
+
+func TestStringToInt(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	weights := evs.StringToInt("WEIGHTS", nil)
+	if err := evs.Parse([]string{"WEIGHTS=b=2,a=0x10"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"a": 16, "b": 2}; !reflect.DeepEqual(*weights, want) {
+		t.Errorf("weights = %v, want %v", *weights, want)
+	}
+	if s := evs.Lookup("WEIGHTS").Value.String(); s != "a=16,b=2" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestMapErrors(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"a=1,a=2", `duplicate key "a"`},
+		{"a=1,b", `"b" is not of the form key=value`},
+		{"=1", "empty key"},
+		{"a=x", `key "a"`},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		m := evs.StringToInt("M", map[string]int{"z": 1})
+		err := evs.Parse([]string{"M=" + test.in})
+		var perr *ParseError
+		if !errors.As(err, &perr) || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Parse(M=%s): want *ParseError containing %q, got %v", test.in, test.want, err)
+		}
+		if (*m)["z"] != 1 {
+			t.Errorf("Parse(M=%s) modified map: %v", test.in, *m)
+		}
+	}
+}
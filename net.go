@@ -0,0 +1,358 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// -- *url.URL Value
+type urlValue struct {
+	p       *url.URL
+	schemes []string
+}
+
+func newURLValue(val *url.URL, p *url.URL, schemes []string) *urlValue {
+	if val != nil {
+		*p = *val
+	}
+	return &urlValue{p, schemes}
+}
+
+func (u *urlValue) Set(s string) error {
+	v, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if len(u.schemes) > 0 {
+		ok := false
+		for _, scheme := range u.schemes {
+			if strings.EqualFold(v.Scheme, scheme) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("scheme %q is not one of %s", v.Scheme, strings.Join(u.schemes, ", "))
+		}
+	}
+	*u.p = *v
+	return nil
+}
+
+func (u *urlValue) Get() interface{} {
+	v := *u.p
+	return &v
+}
+
+func (u *urlValue) save() func() {
+	v := *u.p
+	return func() { *u.p = v }
+}
+
+func (u *urlValue) String() string {
+	if u == nil || u.p == nil {
+		return ""
+	}
+	return u.p.String()
+}
+
+// -- net.IP Value
+type ipValue net.IP
+
+func newIPValue(val net.IP, p *net.IP) *ipValue {
+	*p = val
+	return (*ipValue)(p)
+}
+
+func (i *ipValue) Set(s string) error {
+	ip := net.ParseIP(strings.TrimSpace(s))
+	if ip == nil {
+		return errors.New("invalid IP address")
+	}
+	*i = ipValue(ip)
+	return nil
+}
+
+func (i *ipValue) Get() interface{} { return net.IP(*i) }
+
+func (i *ipValue) String() string {
+	if i == nil || len(*i) == 0 {
+		return ""
+	}
+	return net.IP(*i).String()
+}
+
+// -- net.IPNet Value
+type ipNetValue net.IPNet
+
+func newIPNetValue(val net.IPNet, p *net.IPNet) *ipNetValue {
+	*p = val
+	return (*ipNetValue)(p)
+}
+
+func (n *ipNetValue) Set(s string) error {
+	_, v, err := net.ParseCIDR(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*n = ipNetValue(*v)
+	return nil
+}
+
+func (n *ipNetValue) Get() interface{} { return net.IPNet(*n) }
+
+func (n *ipNetValue) String() string {
+	if n == nil || len(n.IP) == 0 {
+		return ""
+	}
+	return (*net.IPNet)(n).String()
+}
+
+// -- host:port Value
+type hostPortValue struct {
+	p           *string
+	defaultPort string
+}
+
+func newHostPortValue(val string, p *string, defaultPort string) *hostPortValue {
+	*p = val
+	return &hostPortValue{p, defaultPort}
+}
+
+func (h *hostPortValue) Set(s string) error {
+	host, port, err := net.SplitHostPort(s)
+	if err != nil && h.defaultPort != "" {
+		// s may be a host without a port, such as example.com, ::1 or [::1].
+		host = s
+		if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+			host = host[1 : len(host)-1]
+		}
+		if _, _, err2 := net.SplitHostPort(net.JoinHostPort(host, h.defaultPort)); err2 == nil {
+			port, err = h.defaultPort, nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if strings.Contains(host, ":") {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return fmt.Errorf("invalid host %q", host)
+		}
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	*h.p = net.JoinHostPort(host, port)
+	return nil
+}
+
+func (h *hostPortValue) Get() interface{} { return *h.p }
+
+func (h *hostPortValue) save() func() {
+	v := *h.p
+	return func() { *h.p = v }
+}
+
+func (h *hostPortValue) String() string {
+	if h == nil || h.p == nil {
+		return ""
+	}
+	return *h.p
+}
+
+// -- netip.AddrPort Value
+type addrPortValue netip.AddrPort
+
+func newAddrPortValue(val netip.AddrPort, p *netip.AddrPort) *addrPortValue {
+	*p = val
+	return (*addrPortValue)(p)
+}
+
+func (a *addrPortValue) Set(s string) error {
+	v, err := netip.ParseAddrPort(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+	*a = addrPortValue(v)
+	return nil
+}
+
+func (a *addrPortValue) Get() interface{} { return netip.AddrPort(*a) }
+
+func (a *addrPortValue) String() string {
+	if a == nil || !netip.AddrPort(*a).IsValid() {
+		return ""
+	}
+	return netip.AddrPort(*a).String()
+}
+
+// URLVar defines a url.URL EnvVar with specified name, and default value.
+// The argument p points to a url.URL variable in which to store the value of the EnvVar.
+// The EnvVar accepts a URL acceptable to url.Parse and, if any schemes are given,
+// with one of those schemes. A nil default is the empty URL.
+func (evs *EnvVarSet) URLVar(p *url.URL, name string, value *url.URL, schemes ...string) {
+	evs.Var(newURLValue(value, p, schemes), name)
+}
+
+// URLVar defines a url.URL EnvVar with specified name, and default value.
+// The argument p points to a url.URL variable in which to store the value of the EnvVar.
+// The EnvVar accepts a URL acceptable to url.Parse and, if any schemes are given,
+// with one of those schemes. A nil default is the empty URL.
+func URLVar(p *url.URL, name string, value *url.URL, schemes ...string) {
+	EnvVars.URLVar(p, name, value, schemes...)
+}
+
+// URL defines a url.URL EnvVar with specified name, and default value.
+// The return value is the address of a url.URL variable that stores the value of the EnvVar.
+// The EnvVar accepts a URL acceptable to url.Parse and, if any schemes are given,
+// with one of those schemes. A nil default is the empty URL.
+func (evs *EnvVarSet) URL(name string, value *url.URL, schemes ...string) *url.URL {
+	p := new(url.URL)
+	evs.URLVar(p, name, value, schemes...)
+	return p
+}
+
+// URL defines a url.URL EnvVar with specified name, and default value.
+// The return value is the address of a url.URL variable that stores the value of the EnvVar.
+// The EnvVar accepts a URL acceptable to url.Parse and, if any schemes are given,
+// with one of those schemes. A nil default is the empty URL.
+func URL(name string, value *url.URL, schemes ...string) *url.URL {
+	return EnvVars.URL(name, value, schemes...)
+}
+
+// IPVar defines a net.IP EnvVar with specified name, and default value.
+// The argument p points to a net.IP variable in which to store the value of the EnvVar.
+// The EnvVar accepts an IPv4 or IPv6 address acceptable to net.ParseIP.
+func (evs *EnvVarSet) IPVar(p *net.IP, name string, value net.IP) {
+	evs.Var(newIPValue(value, p), name)
+}
+
+// IPVar defines a net.IP EnvVar with specified name, and default value.
+// The argument p points to a net.IP variable in which to store the value of the EnvVar.
+// The EnvVar accepts an IPv4 or IPv6 address acceptable to net.ParseIP.
+func IPVar(p *net.IP, name string, value net.IP) {
+	EnvVars.IPVar(p, name, value)
+}
+
+// IP defines a net.IP EnvVar with specified name, and default value.
+// The return value is the address of a net.IP variable that stores the value of the EnvVar.
+// The EnvVar accepts an IPv4 or IPv6 address acceptable to net.ParseIP.
+func (evs *EnvVarSet) IP(name string, value net.IP) *net.IP {
+	p := new(net.IP)
+	evs.IPVar(p, name, value)
+	return p
+}
+
+// IP defines a net.IP EnvVar with specified name, and default value.
+// The return value is the address of a net.IP variable that stores the value of the EnvVar.
+// The EnvVar accepts an IPv4 or IPv6 address acceptable to net.ParseIP.
+func IP(name string, value net.IP) *net.IP {
+	return EnvVars.IP(name, value)
+}
+
+// IPNetVar defines a net.IPNet EnvVar with specified name, and default value.
+// The argument p points to a net.IPNet variable in which to store the value of the EnvVar.
+// The EnvVar accepts a network in CIDR notation, such as 10.0.0.0/8, acceptable to
+// net.ParseCIDR.
+func (evs *EnvVarSet) IPNetVar(p *net.IPNet, name string, value net.IPNet) {
+	evs.Var(newIPNetValue(value, p), name)
+}
+
+// IPNetVar defines a net.IPNet EnvVar with specified name, and default value.
+// The argument p points to a net.IPNet variable in which to store the value of the EnvVar.
+// The EnvVar accepts a network in CIDR notation, such as 10.0.0.0/8, acceptable to
+// net.ParseCIDR.
+func IPNetVar(p *net.IPNet, name string, value net.IPNet) {
+	EnvVars.IPNetVar(p, name, value)
+}
+
+// IPNet defines a net.IPNet EnvVar with specified name, and default value.
+// The return value is the address of a net.IPNet variable that stores the value of the EnvVar.
+// The EnvVar accepts a network in CIDR notation, such as 10.0.0.0/8, acceptable to
+// net.ParseCIDR.
+func (evs *EnvVarSet) IPNet(name string, value net.IPNet) *net.IPNet {
+	p := new(net.IPNet)
+	evs.IPNetVar(p, name, value)
+	return p
+}
+
+// IPNet defines a net.IPNet EnvVar with specified name, and default value.
+// The return value is the address of a net.IPNet variable that stores the value of the EnvVar.
+// The EnvVar accepts a network in CIDR notation, such as 10.0.0.0/8, acceptable to
+// net.ParseCIDR.
+func IPNet(name string, value net.IPNet) *net.IPNet {
+	return EnvVars.IPNet(name, value)
+}
+
+// HostPortVar defines a host:port string EnvVar with specified name, and default value.
+// The argument p points to a host:port string variable in which to store the value of the EnvVar.
+// The EnvVar accepts a host and numeric port acceptable to net.SplitHostPort; if
+// defaultPort is not empty, the port may be omitted and defaults to defaultPort.
+func (evs *EnvVarSet) HostPortVar(p *string, name string, value string, defaultPort string) {
+	evs.Var(newHostPortValue(value, p, defaultPort), name)
+}
+
+// HostPortVar defines a host:port string EnvVar with specified name, and default value.
+// The argument p points to a host:port string variable in which to store the value of the EnvVar.
+// The EnvVar accepts a host and numeric port acceptable to net.SplitHostPort; if
+// defaultPort is not empty, the port may be omitted and defaults to defaultPort.
+func HostPortVar(p *string, name string, value string, defaultPort string) {
+	EnvVars.HostPortVar(p, name, value, defaultPort)
+}
+
+// HostPort defines a host:port string EnvVar with specified name, and default value.
+// The return value is the address of a host:port string variable that stores the value of the EnvVar.
+// The EnvVar accepts a host and numeric port acceptable to net.SplitHostPort; if
+// defaultPort is not empty, the port may be omitted and defaults to defaultPort.
+func (evs *EnvVarSet) HostPort(name string, value string, defaultPort string) *string {
+	p := new(string)
+	evs.HostPortVar(p, name, value, defaultPort)
+	return p
+}
+
+// HostPort defines a host:port string EnvVar with specified name, and default value.
+// The return value is the address of a host:port string variable that stores the value of the EnvVar.
+// The EnvVar accepts a host and numeric port acceptable to net.SplitHostPort; if
+// defaultPort is not empty, the port may be omitted and defaults to defaultPort.
+func HostPort(name string, value string, defaultPort string) *string {
+	return EnvVars.HostPort(name, value, defaultPort)
+}
+
+// AddrPortVar defines a netip.AddrPort EnvVar with specified name, and default value.
+// The argument p points to a netip.AddrPort variable in which to store the value of the EnvVar.
+// The EnvVar accepts an IP address and port acceptable to netip.ParseAddrPort.
+func (evs *EnvVarSet) AddrPortVar(p *netip.AddrPort, name string, value netip.AddrPort) {
+	evs.Var(newAddrPortValue(value, p), name)
+}
+
+// AddrPortVar defines a netip.AddrPort EnvVar with specified name, and default value.
+// The argument p points to a netip.AddrPort variable in which to store the value of the EnvVar.
+// The EnvVar accepts an IP address and port acceptable to netip.ParseAddrPort.
+func AddrPortVar(p *netip.AddrPort, name string, value netip.AddrPort) {
+	EnvVars.AddrPortVar(p, name, value)
+}
+
+// AddrPort defines a netip.AddrPort EnvVar with specified name, and default value.
+// The return value is the address of a netip.AddrPort variable that stores the value of the EnvVar.
+// The EnvVar accepts an IP address and port acceptable to netip.ParseAddrPort.
+func (evs *EnvVarSet) AddrPort(name string, value netip.AddrPort) *netip.AddrPort {
+	p := new(netip.AddrPort)
+	evs.AddrPortVar(p, name, value)
+	return p
+}
+
+// AddrPort defines a netip.AddrPort EnvVar with specified name, and default value.
+// The return value is the address of a netip.AddrPort variable that stores the value of the EnvVar.
+// The EnvVar accepts an IP address and port acceptable to netip.ParseAddrPort.
+func AddrPort(name string, value netip.AddrPort) *netip.AddrPort {
+	return EnvVars.AddrPort(name, value)
+}
@@ -0,0 +1,148 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestNetRoundTrip(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	u := evs.URL("URL", nil, "http", "https")
+	ip := evs.IP("IP", nil)
+	cidr := evs.IPNet("CIDR", net.IPNet{})
+	hp := evs.HostPort("HOSTPORT", "", "80")
+	ap := evs.AddrPort("ADDRPORT", netip.AddrPort{})
+	env := []string{
+		"URL=HTTPS://user@example.com:8443/path?q=1",
+		"IP=::ffff:10.0.0.1",
+		"CIDR=10.1.2.3/8",
+		"HOSTPORT=example.com",
+		"ADDRPORT=[::1]:53",
+	}
+	if err := evs.Parse(env); err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "example.com:8443" || u.Scheme != "https" {
+		t.Errorf("URL = %v", u)
+	}
+	if !ip.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("IP = %v", ip)
+	}
+	if cidr.String() != "10.0.0.0/8" {
+		t.Errorf("CIDR = %v", cidr)
+	}
+	if *hp != "example.com:80" {
+		t.Errorf("HOSTPORT = %v", *hp)
+	}
+	if *ap != netip.MustParseAddrPort("[::1]:53") {
+		t.Errorf("ADDRPORT = %v", *ap)
+	}
+
+	if v, ok := Get[*url.URL](evs, "URL"); !ok || v.Path != "/path" {
+		t.Errorf("Get[*url.URL] = %v, %v", v, ok)
+	}
+	if v, ok := Get[net.IP](evs, "IP"); !ok || !v.Equal(*ip) {
+		t.Errorf("Get[net.IP] = %v, %v", v, ok)
+	}
+	if v, ok := Get[net.IPNet](evs, "CIDR"); !ok || v.String() != cidr.String() {
+		t.Errorf("Get[net.IPNet] = %v, %v", v, ok)
+	}
+	if v, ok := Get[string](evs, "HOSTPORT"); !ok || v != *hp {
+		t.Errorf("Get[string] = %v, %v", v, ok)
+	}
+	if v, ok := Get[netip.AddrPort](evs, "ADDRPORT"); !ok || v != *ap {
+		t.Errorf("Get[netip.AddrPort] = %v, %v", v, ok)
+	}
+
+	// Each String must be accepted by Set and yield the same value.
+	evs.VisitAll(func(envVar *EnvVar) {
+		s := envVar.Value.String()
+		if err := envVar.Value.Set(s); err != nil {
+			t.Errorf("%s: Set(%q): %v", envVar.Name, s, err)
+		} else if got := envVar.Value.String(); got != s {
+			t.Errorf("%s: round trip of %q gave %q", envVar.Name, s, got)
+		}
+	})
+}
+
+func TestNetDefaults(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	def, _ := url.Parse("http://localhost")
+	u := evs.URL("URL", def)
+	u.Path = "/changed"
+	if def.Path != "" {
+		t.Error("URL shares its default")
+	}
+	evs.IP("IP", nil)
+	evs.IPNet("CIDR", net.IPNet{})
+	evs.AddrPort("ADDRPORT", netip.AddrPort{})
+	for _, name := range []string{"IP", "CIDR", "ADDRPORT"} {
+		if def := evs.Lookup(name).DefValue; def != "" {
+			t.Errorf("%s: zero default shown as %q", name, def)
+		}
+	}
+}
+
+func TestHostPort(t *testing.T) {
+	tests := []struct {
+		in, defaultPort, want string // empty want means an error
+	}{
+		{"example.com:8080", "", "example.com:8080"},
+		{"example.com", "", ""},
+		{"example.com", "80", "example.com:80"},
+		{":8080", "", ":8080"},
+		{"::1", "80", "[::1]:80"},
+		{"[::1]", "80", "[::1]:80"},
+		{"[::1]:443", "80", "[::1]:443"},
+		{"example.com:http", "80", ""},
+		{"example.com:70000", "80", ""},
+		{"example.com:", "80", ""},
+		{"a:b:c", "80", ""},
+		{"[::1", "80", ""},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		hp := evs.HostPort("ADDR", "old:1", test.defaultPort)
+		err := evs.Parse([]string{"ADDR=" + test.in})
+		switch {
+		case test.want == "" && err == nil:
+			t.Errorf("%q (default port %q): expected error, got %q", test.in, test.defaultPort, *hp)
+		case test.want == "" && *hp != "old:1":
+			t.Errorf("%q: rejected value changed variable to %q", test.in, *hp)
+		case test.want != "" && (err != nil || *hp != test.want):
+			t.Errorf("%q (default port %q): got %q, %v, want %q", test.in, test.defaultPort, *hp, err, test.want)
+		}
+	}
+}
+
+func TestNetErrors(t *testing.T) {
+	tests := []struct {
+		define func(evs *EnvVarSet)
+		value  string
+	}{
+		{func(evs *EnvVarSet) { evs.URL("V", nil, "https") }, "http://example.com"},
+		{func(evs *EnvVarSet) { evs.URL("V", nil, "https") }, "example.com"},
+		{func(evs *EnvVarSet) { evs.URL("V", nil) }, "http://[::1"},
+		{func(evs *EnvVarSet) { evs.IP("V", nil) }, "10.0.0"},
+		{func(evs *EnvVarSet) { evs.IPNet("V", net.IPNet{}) }, "10.0.0.0"},
+		{func(evs *EnvVarSet) { evs.AddrPort("V", netip.AddrPort{}) }, "example.com:80"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		test.define(evs)
+		if err := evs.Parse([]string{"V=" + test.value}); err == nil {
+			t.Errorf("%T: expected error for %q", evs.Lookup("V").Value, test.value)
+		}
+	}
+}
@@ -0,0 +1,528 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"math/big"
+	"strconv"
+)
+
+// -- int8 Value
+type int8Value int8
+
+func newInt8Value(val int8, p *int8) *int8Value {
+	*p = val
+	return (*int8Value)(p)
+}
+
+func (i *int8Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 8)
+	if err != nil {
+		return err
+	}
+	*i = int8Value(v)
+	return nil
+}
+
+func (i *int8Value) Get() interface{} { return int8(*i) }
+
+func (i *int8Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// -- int16 Value
+type int16Value int16
+
+func newInt16Value(val int16, p *int16) *int16Value {
+	*p = val
+	return (*int16Value)(p)
+}
+
+func (i *int16Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 16)
+	if err != nil {
+		return err
+	}
+	*i = int16Value(v)
+	return nil
+}
+
+func (i *int16Value) Get() interface{} { return int16(*i) }
+
+func (i *int16Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// -- int32 Value
+type int32Value int32
+
+func newInt32Value(val int32, p *int32) *int32Value {
+	*p = val
+	return (*int32Value)(p)
+}
+
+func (i *int32Value) Set(s string) error {
+	v, err := strconv.ParseInt(s, 0, 32)
+	if err != nil {
+		return err
+	}
+	*i = int32Value(v)
+	return nil
+}
+
+func (i *int32Value) Get() interface{} { return int32(*i) }
+
+func (i *int32Value) String() string { return strconv.FormatInt(int64(*i), 10) }
+
+// -- uint8 Value
+type uint8Value uint8
+
+func newUint8Value(val uint8, p *uint8) *uint8Value {
+	*p = val
+	return (*uint8Value)(p)
+}
+
+func (i *uint8Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return err
+	}
+	*i = uint8Value(v)
+	return nil
+}
+
+func (i *uint8Value) Get() interface{} { return uint8(*i) }
+
+func (i *uint8Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- uint16 Value
+type uint16Value uint16
+
+func newUint16Value(val uint16, p *uint16) *uint16Value {
+	*p = val
+	return (*uint16Value)(p)
+}
+
+func (i *uint16Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 16)
+	if err != nil {
+		return err
+	}
+	*i = uint16Value(v)
+	return nil
+}
+
+func (i *uint16Value) Get() interface{} { return uint16(*i) }
+
+func (i *uint16Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- uint32 Value
+type uint32Value uint32
+
+func newUint32Value(val uint32, p *uint32) *uint32Value {
+	*p = val
+	return (*uint32Value)(p)
+}
+
+func (i *uint32Value) Set(s string) error {
+	v, err := strconv.ParseUint(s, 0, 32)
+	if err != nil {
+		return err
+	}
+	*i = uint32Value(v)
+	return nil
+}
+
+func (i *uint32Value) Get() interface{} { return uint32(*i) }
+
+func (i *uint32Value) String() string { return strconv.FormatUint(uint64(*i), 10) }
+
+// -- float32 Value
+type float32Value float32
+
+func newFloat32Value(val float32, p *float32) *float32Value {
+	*p = val
+	return (*float32Value)(p)
+}
+
+func (f *float32Value) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		return err
+	}
+	*f = float32Value(v)
+	return nil
+}
+
+func (f *float32Value) Get() interface{} { return float32(*f) }
+
+func (f *float32Value) String() string { return strconv.FormatFloat(float64(*f), 'g', -1, 32) }
+
+// -- complex128 Value
+type complex128Value complex128
+
+func newComplex128Value(val complex128, p *complex128) *complex128Value {
+	*p = val
+	return (*complex128Value)(p)
+}
+
+func (c *complex128Value) Set(s string) error {
+	v, err := strconv.ParseComplex(s, 128)
+	if err != nil {
+		return err
+	}
+	*c = complex128Value(v)
+	return nil
+}
+
+func (c *complex128Value) Get() interface{} { return complex128(*c) }
+
+func (c *complex128Value) String() string { return strconv.FormatComplex(complex128(*c), 'g', -1, 128) }
+
+// -- *big.Int Value
+type bigIntValue struct{ p *big.Int }
+
+func newBigIntValue(val *big.Int, p *big.Int) *bigIntValue {
+	if val == nil {
+		val = new(big.Int)
+	}
+	p.Set(val)
+	return &bigIntValue{p}
+}
+
+func (b *bigIntValue) Set(s string) error {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return strconv.ErrSyntax
+	}
+	b.p.Set(v)
+	return nil
+}
+
+func (b *bigIntValue) Get() interface{} { return new(big.Int).Set(b.p) }
+
+func (b *bigIntValue) save() func() {
+	v := new(big.Int).Set(b.p)
+	return func() { b.p.Set(v) }
+}
+
+func (b *bigIntValue) String() string {
+	if b == nil || b.p == nil {
+		return ""
+	}
+	return b.p.String()
+}
+
+// -- *big.Float Value
+type bigFloatValue struct{ p *big.Float }
+
+func newBigFloatValue(val *big.Float, p *big.Float) *bigFloatValue {
+	if val == nil {
+		val = new(big.Float)
+	}
+	p.Set(val)
+	return &bigFloatValue{p}
+}
+
+// Set parses s at the precision of the variable, or at 64 bits if the
+// variable has no precision yet.
+func (b *bigFloatValue) Set(s string) error {
+	prec := b.p.Prec()
+	if prec == 0 {
+		prec = 64
+	}
+	v, _, err := big.ParseFloat(s, 0, prec, b.p.Mode())
+	if err != nil {
+		return err
+	}
+	b.p.Set(v)
+	return nil
+}
+
+func (b *bigFloatValue) Get() interface{} { return new(big.Float).Copy(b.p) }
+
+func (b *bigFloatValue) save() func() {
+	v := new(big.Float).Copy(b.p)
+	return func() { b.p.Copy(v) }
+}
+
+func (b *bigFloatValue) String() string {
+	if b == nil || b.p == nil {
+		return ""
+	}
+	return b.p.Text('g', -1)
+}
+
+// Int8Var defines an int8 EnvVar with specified name, and default value.
+// The argument p points to an int8 variable in which to store the value of the EnvVar.
+func (evs *EnvVarSet) Int8Var(p *int8, name string, value int8) {
+	evs.Var(newInt8Value(value, p), name)
+}
+
+// Int8Var defines an int8 EnvVar with specified name, and default value.
+// The argument p points to an int8 variable in which to store the value of the EnvVar.
+func Int8Var(p *int8, name string, value int8) {
+	EnvVars.Var(newInt8Value(value, p), name)
+}
+
+// Int8 defines an int8 EnvVar with specified name, and default value.
+// The return value is the address of an int8 variable that stores the value of the EnvVar.
+func (evs *EnvVarSet) Int8(name string, value int8) *int8 {
+	p := new(int8)
+	evs.Int8Var(p, name, value)
+	return p
+}
+
+// Int8 defines an int8 EnvVar with specified name, and default value.
+// The return value is the address of an int8 variable that stores the value of the EnvVar.
+func Int8(name string, value int8) *int8 {
+	return EnvVars.Int8(name, value)
+}
+
+// Int16Var defines an int16 EnvVar with specified name, and default value.
+// The argument p points to an int16 variable in which to store the value of the EnvVar.
+func (evs *EnvVarSet) Int16Var(p *int16, name string, value int16) {
+	evs.Var(newInt16Value(value, p), name)
+}
+
+// Int16Var defines an int16 EnvVar with specified name, and default value.
+// The argument p points to an int16 variable in which to store the value of the EnvVar.
+func Int16Var(p *int16, name string, value int16) {
+	EnvVars.Var(newInt16Value(value, p), name)
+}
+
+// Int16 defines an int16 EnvVar with specified name, and default value.
+// The return value is the address of an int16 variable that stores the value of the EnvVar.
+func (evs *EnvVarSet) Int16(name string, value int16) *int16 {
+	p := new(int16)
+	evs.Int16Var(p, name, value)
+	return p
+}
+
+// Int16 defines an int16 EnvVar with specified name, and default value.
+// The return value is the address of an int16 variable that stores the value of the EnvVar.
+func Int16(name string, value int16) *int16 {
+	return EnvVars.Int16(name, value)
+}
+
+// Int32Var defines an int32 EnvVar with specified name, and default value.
+// The argument p points to an int32 variable in which to store the value of the EnvVar.
+func (evs *EnvVarSet) Int32Var(p *int32, name string, value int32) {
+	evs.Var(newInt32Value(value, p), name)
+}
+
+// Int32Var defines an int32 EnvVar with specified name, and default value.
+// The argument p points to an int32 variable in which to store the value of the EnvVar.
+func Int32Var(p *int32, name string, value int32) {
+	EnvVars.Var(newInt32Value(value, p), name)
+}
+
+// Int32 defines an int32 EnvVar with specified name, and default value.
+// The return value is the address of an int32 variable that stores the value of the EnvVar.
+func (evs *EnvVarSet) Int32(name string, value int32) *int32 {
+	p := new(int32)
+	evs.Int32Var(p, name, value)
+	return p
+}
+
+// Int32 defines an int32 EnvVar with specified name, and default value.
+// The return value is the address of an int32 variable that stores the value of the EnvVar.
+func Int32(name string, value int32) *int32 {
+	return EnvVars.Int32(name, value)
+}
+
+// Uint8Var defines a uint8 EnvVar with specified name, and default value.
+// The argument p points to a uint8 variable in which to store the value of the EnvVar.
+func (evs *EnvVarSet) Uint8Var(p *uint8, name string, value uint8) {
+	evs.Var(newUint8Value(value, p), name)
+}
+
+// Uint8Var defines a uint8 EnvVar with specified name, and default value.
+// The argument p points to a uint8 variable in which to store the value of the EnvVar.
+func Uint8Var(p *uint8, name string, value uint8) {
+	EnvVars.Var(newUint8Value(value, p), name)
+}
+
+// Uint8 defines a uint8 EnvVar with specified name, and default value.
+// The return value is the address of a uint8 variable that stores the value of the EnvVar.
+func (evs *EnvVarSet) Uint8(name string, value uint8) *uint8 {
+	p := new(uint8)
+	evs.Uint8Var(p, name, value)
+	return p
+}
+
+// Uint8 defines a uint8 EnvVar with specified name, and default value.
+// The return value is the address of a uint8 variable that stores the value of the EnvVar.
+func Uint8(name string, value uint8) *uint8 {
+	return EnvVars.Uint8(name, value)
+}
+
+// Uint16Var defines a uint16 EnvVar with specified name, and default value.
+// The argument p points to a uint16 variable in which to store the value of the EnvVar.
+func (evs *EnvVarSet) Uint16Var(p *uint16, name string, value uint16) {
+	evs.Var(newUint16Value(value, p), name)
+}
+
+// Uint16Var defines a uint16 EnvVar with specified name, and default value.
+// The argument p points to a uint16 variable in which to store the value of the EnvVar.
+func Uint16Var(p *uint16, name string, value uint16) {
+	EnvVars.Var(newUint16Value(value, p), name)
+}
+
+// Uint16 defines a uint16 EnvVar with specified name, and default value.
+// The return value is the address of a uint16 variable that stores the value of the EnvVar.
+func (evs *EnvVarSet) Uint16(name string, value uint16) *uint16 {
+	p := new(uint16)
+	evs.Uint16Var(p, name, value)
+	return p
+}
+
+// Uint16 defines a uint16 EnvVar with specified name, and default value.
+// The return value is the address of a uint16 variable that stores the value of the EnvVar.
+func Uint16(name string, value uint16) *uint16 {
+	return EnvVars.Uint16(name, value)
+}
+
+// Uint32Var defines a uint32 EnvVar with specified name, and default value.
+// The argument p points to a uint32 variable in which to store the value of the EnvVar.
+func (evs *EnvVarSet) Uint32Var(p *uint32, name string, value uint32) {
+	evs.Var(newUint32Value(value, p), name)
+}
+
+// Uint32Var defines a uint32 EnvVar with specified name, and default value.
+// The argument p points to a uint32 variable in which to store the value of the EnvVar.
+func Uint32Var(p *uint32, name string, value uint32) {
+	EnvVars.Var(newUint32Value(value, p), name)
+}
+
+// Uint32 defines a uint32 EnvVar with specified name, and default value.
+// The return value is the address of a uint32 variable that stores the value of the EnvVar.
+func (evs *EnvVarSet) Uint32(name string, value uint32) *uint32 {
+	p := new(uint32)
+	evs.Uint32Var(p, name, value)
+	return p
+}
+
+// Uint32 defines a uint32 EnvVar with specified name, and default value.
+// The return value is the address of a uint32 variable that stores the value of the EnvVar.
+func Uint32(name string, value uint32) *uint32 {
+	return EnvVars.Uint32(name, value)
+}
+
+// Float32Var defines a float32 EnvVar with specified name, and default value.
+// The argument p points to a float32 variable in which to store the value of the EnvVar.
+func (evs *EnvVarSet) Float32Var(p *float32, name string, value float32) {
+	evs.Var(newFloat32Value(value, p), name)
+}
+
+// Float32Var defines a float32 EnvVar with specified name, and default value.
+// The argument p points to a float32 variable in which to store the value of the EnvVar.
+func Float32Var(p *float32, name string, value float32) {
+	EnvVars.Var(newFloat32Value(value, p), name)
+}
+
+// Float32 defines a float32 EnvVar with specified name, and default value.
+// The return value is the address of a float32 variable that stores the value of the EnvVar.
+func (evs *EnvVarSet) Float32(name string, value float32) *float32 {
+	p := new(float32)
+	evs.Float32Var(p, name, value)
+	return p
+}
+
+// Float32 defines a float32 EnvVar with specified name, and default value.
+// The return value is the address of a float32 variable that stores the value of the EnvVar.
+func Float32(name string, value float32) *float32 {
+	return EnvVars.Float32(name, value)
+}
+
+// Complex128Var defines a complex128 EnvVar with specified name, and default value.
+// The argument p points to a complex128 variable in which to store the value of the EnvVar.
+// The EnvVar accepts a value acceptable to strconv.ParseComplex, such as 1+2i.
+func (evs *EnvVarSet) Complex128Var(p *complex128, name string, value complex128) {
+	evs.Var(newComplex128Value(value, p), name)
+}
+
+// Complex128Var defines a complex128 EnvVar with specified name, and default value.
+// The argument p points to a complex128 variable in which to store the value of the EnvVar.
+// The EnvVar accepts a value acceptable to strconv.ParseComplex, such as 1+2i.
+func Complex128Var(p *complex128, name string, value complex128) {
+	EnvVars.Var(newComplex128Value(value, p), name)
+}
+
+// Complex128 defines a complex128 EnvVar with specified name, and default value.
+// The return value is the address of a complex128 variable that stores the value of the EnvVar.
+// The EnvVar accepts a value acceptable to strconv.ParseComplex, such as 1+2i.
+func (evs *EnvVarSet) Complex128(name string, value complex128) *complex128 {
+	p := new(complex128)
+	evs.Complex128Var(p, name, value)
+	return p
+}
+
+// Complex128 defines a complex128 EnvVar with specified name, and default value.
+// The return value is the address of a complex128 variable that stores the value of the EnvVar.
+// The EnvVar accepts a value acceptable to strconv.ParseComplex, such as 1+2i.
+func Complex128(name string, value complex128) *complex128 {
+	return EnvVars.Complex128(name, value)
+}
+
+// BigIntVar defines a big.Int EnvVar with specified name, and default value.
+// The argument p points to a big.Int variable in which to store the value of the EnvVar.
+// The EnvVar accepts an integer of any size in the syntax of big.Int.SetString with base 0.
+func (evs *EnvVarSet) BigIntVar(p *big.Int, name string, value *big.Int) {
+	evs.Var(newBigIntValue(value, p), name)
+}
+
+// BigIntVar defines a big.Int EnvVar with specified name, and default value.
+// The argument p points to a big.Int variable in which to store the value of the EnvVar.
+// The EnvVar accepts an integer of any size in the syntax of big.Int.SetString with base 0.
+func BigIntVar(p *big.Int, name string, value *big.Int) {
+	EnvVars.Var(newBigIntValue(value, p), name)
+}
+
+// BigInt defines a big.Int EnvVar with specified name, and default value.
+// The return value is the address of a big.Int variable that stores the value of the EnvVar.
+// The EnvVar accepts an integer of any size in the syntax of big.Int.SetString with base 0.
+func (evs *EnvVarSet) BigInt(name string, value *big.Int) *big.Int {
+	p := new(big.Int)
+	evs.BigIntVar(p, name, value)
+	return p
+}
+
+// BigInt defines a big.Int EnvVar with specified name, and default value.
+// The return value is the address of a big.Int variable that stores the value of the EnvVar.
+// The EnvVar accepts an integer of any size in the syntax of big.Int.SetString with base 0.
+func BigInt(name string, value *big.Int) *big.Int {
+	return EnvVars.BigInt(name, value)
+}
+
+// BigFloatVar defines a big.Float EnvVar with specified name, and default value.
+// The argument p points to a big.Float variable in which to store the value of the EnvVar.
+// The EnvVar accepts a number in the syntax of big.ParseFloat with base 0, parsed at the
+// precision of the default value or, if it has none, at 64 bits.
+func (evs *EnvVarSet) BigFloatVar(p *big.Float, name string, value *big.Float) {
+	evs.Var(newBigFloatValue(value, p), name)
+}
+
+// BigFloatVar defines a big.Float EnvVar with specified name, and default value.
+// The argument p points to a big.Float variable in which to store the value of the EnvVar.
+// The EnvVar accepts a number in the syntax of big.ParseFloat with base 0, parsed at the
+// precision of the default value or, if it has none, at 64 bits.
+func BigFloatVar(p *big.Float, name string, value *big.Float) {
+	EnvVars.Var(newBigFloatValue(value, p), name)
+}
+
+// BigFloat defines a big.Float EnvVar with specified name, and default value.
+// The return value is the address of a big.Float variable that stores the value of the EnvVar.
+// The EnvVar accepts a number in the syntax of big.ParseFloat with base 0, parsed at the
+// precision of the default value or, if it has none, at 64 bits.
+func (evs *EnvVarSet) BigFloat(name string, value *big.Float) *big.Float {
+	p := new(big.Float)
+	evs.BigFloatVar(p, name, value)
+	return p
+}
+
+// BigFloat defines a big.Float EnvVar with specified name, and default value.
+// The return value is the address of a big.Float variable that stores the value of the EnvVar.
+// The EnvVar accepts a number in the syntax of big.ParseFloat with base 0, parsed at the
+// precision of the default value or, if it has none, at 64 bits.
+func BigFloat(name string, value *big.Float) *big.Float {
+	return EnvVars.BigFloat(name, value)
+}
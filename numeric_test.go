@@ -0,0 +1,125 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"strconv"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestSizedNumbers(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.Int8("I8", 0)
+	evs.Int16("I16", 0)
+	evs.Int32("I32", 0)
+	evs.Uint8("U8", 0)
+	evs.Uint16("U16", 0)
+	evs.Uint32("U32", 0)
+	evs.Float32("F32", 0)
+	evs.Complex128("C128", 0)
+	env := []string{
+		"I8=-128",
+		"I16=0x7fff",
+		"I32=-2147483648",
+		"U8=255",
+		"U16=65535",
+		"U32=4294967295",
+		"F32=0.1",
+		"C128=1+2i",
+	}
+	if err := evs.Parse(env); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"I8":   int8(-128),
+		"I16":  int16(32767),
+		"I32":  int32(-2147483648),
+		"U8":   uint8(255),
+		"U16":  uint16(65535),
+		"U32":  uint32(4294967295),
+		"F32":  float32(0.1),
+		"C128": complex(1, 2),
+	}
+	evs.VisitAll(func(envVar *EnvVar) {
+		if got := envVar.Value.(Getter).Get(); got != want[envVar.Name] {
+			t.Errorf("%s: Get() = %#v, want %#v", envVar.Name, got, want[envVar.Name])
+		}
+	})
+	if s := evs.Lookup("F32").Value.String(); s != "0.1" {
+		t.Errorf("F32: String() = %q, want 0.1", s)
+	}
+	if s := evs.Lookup("C128").Value.String(); s != "(1+2i)" {
+		t.Errorf("C128: String() = %q, want (1+2i)", s)
+	}
+	if port, ok := Get[uint16](evs, "U16"); !ok || port != 65535 {
+		t.Errorf("Get[uint16] = %v, %v", port, ok)
+	}
+}
+
+func TestSizedNumberOverflow(t *testing.T) {
+	tests := []struct {
+		define func(evs *EnvVarSet)
+		value  string
+	}{
+		{func(evs *EnvVarSet) { evs.Int8("V", 0) }, "128"},
+		{func(evs *EnvVarSet) { evs.Int8("V", 0) }, "-129"},
+		{func(evs *EnvVarSet) { evs.Int16("V", 0) }, "32768"},
+		{func(evs *EnvVarSet) { evs.Int32("V", 0) }, "2147483648"},
+		{func(evs *EnvVarSet) { evs.Uint8("V", 0) }, "256"},
+		{func(evs *EnvVarSet) { evs.Uint16("V", 0) }, "65536"},
+		{func(evs *EnvVarSet) { evs.Uint32("V", 0) }, "4294967296"},
+		{func(evs *EnvVarSet) { evs.Float32("V", 0) }, "1e39"},
+		{func(evs *EnvVarSet) { evs.Complex128("V", 0) }, "1e309+1i"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		test.define(evs)
+		err := evs.Parse([]string{"V=" + test.value})
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("%s: got %v, want range error", test.value, err)
+		}
+		if s := evs.Lookup("V").Value.String(); s != "0" && s != "(0+0i)" {
+			t.Errorf("%s: rejected value changed variable to %s", test.value, s)
+		}
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	n := evs.BigInt("N", big.NewInt(1))
+	f := evs.BigFloat("F", new(big.Float).SetPrec(200))
+	if err := evs.Parse([]string{"N=0x1000000000000000000000000", "F=0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := new(big.Int).SetString("79228162514264337593543950336", 10); n.Cmp(want) != 0 {
+		t.Errorf("N = %v, want %v", n, want)
+	}
+	if f.Prec() != 200 {
+		t.Errorf("F has precision %d, want 200", f.Prec())
+	}
+	if s := evs.Lookup("F").Value.String(); s != "0.1" {
+		t.Errorf("F: String() = %q, want 0.1", s)
+	}
+	if v, ok := Get[*big.Int](evs, "N"); !ok || v.Cmp(n) != 0 {
+		t.Errorf("Get[*big.Int] = %v, %v", v, ok)
+	}
+
+	if err := evs.Parse([]string{"N=12", "F=x"}); err == nil {
+		t.Fatal("expected error for invalid big.Float")
+	}
+	if n.BitLen() != 97 {
+		t.Errorf("failed parse changed N to %v", n)
+	}
+	if err := evs.Parse([]string{"N=1.5"}); err == nil {
+		t.Error("expected error for invalid big.Int")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import "strconv"
+
+// An OriginKind identifies where the value of an EnvVar came from.
+type OriginKind int
+
+// These are the kinds of Origin.
+const (
+	OriginDefault OriginKind = iota // the EnvVar's default; it has not been set
+	OriginEnviron                   // an environment parsed by Parse or Reparse
+	OriginDotenv                    // a definition read by ParseFile or ParseReader
+	OriginFile                      // a file named by an env var with the set's file suffix
+	OriginSet                       // a call to Set
+	OriginSource                    // a Source, other than an environment or dotenv file, parsed by ParseSources
+)
+
+var originKindNames = [...]string{
+	OriginDefault: "default",
+	OriginEnviron: "environment",
+	OriginDotenv:  "dotenv",
+	OriginFile:    "file",
+	OriginSet:     "Set",
+	OriginSource:  "source",
+}
+
+func (k OriginKind) String() string {
+	if k < 0 || int(k) >= len(originKindNames) {
+		return "OriginKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return originKindNames[k]
+}
+
+// An Origin records where the value of an EnvVar came from. The zero
+// Origin is that of an EnvVar holding its default.
+type Origin struct {
+	Kind   OriginKind
+	Source string // name of the Source of the value, if parsed by ParseSources
+	File   string // dotenv file of the definition, or file holding the value
+	Line   int    // line of the definition in a dotenv file
+	Raw    string // text given to the Value's Set method; empty if the EnvVar is secret
+}
+
+// String describes the origin, such as "environment", "dotenv .env:3" or
+// "source defaults".
+func (o Origin) String() string {
+	switch o.Kind {
+	case OriginDotenv:
+		return "dotenv " + location(o.File, o.Line)
+	case OriginFile:
+		return "file " + o.File
+	case OriginSource:
+		return "source " + o.Source
+	}
+	return o.Kind.String()
+}
+
+// setOrigin records that the value of envVar, given to its Set method as
+// raw, came from origin.
+func (envVar *EnvVar) setOrigin(origin Origin, raw string) {
+	if !envVar.Secret {
+		origin.Raw = raw
+	}
+	envVar.Origin = origin
+}
+
+// entryOrigin returns the origin of a value given by e.
+func entryOrigin(e entry) Origin {
+	return Origin{Kind: e.kind, Source: e.source, File: e.file, Line: e.line}
+}
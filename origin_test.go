@@ -0,0 +1,96 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestOrigin(t *testing.T) {
+	dir := t.TempDir()
+	dotenv := filepath.Join(dir, ".env")
+	secretFile := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(dotenv, []byte("# config\nHOST=example.com\nPORT=${BASE}1\nBASE=808\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(secretFile, []byte("hunter2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetFileSuffix("_FILE")
+	evs.SetExpand(true)
+	evs.Int("PORT", 80)
+	evs.String("HOST", "localhost")
+	evs.String("USER", "")
+	evs.String("PASSWORD", "")
+	evs.Int("WORKERS", 1)
+	evs.Int("RETRIES", 3)
+	evs.Redact("PASSWORD")
+
+	if err := evs.Parse([]string{"USER=admin", "PASSWORD_FILE=" + secretFile}); err != nil {
+		t.Fatal(err)
+	}
+	if err := evs.ParseFile(dotenv); err != nil {
+		t.Fatal(err)
+	}
+	if err := evs.Set("WORKERS", "4"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		want Origin
+		str  string
+	}{
+		{"PORT", Origin{Kind: OriginDotenv, File: dotenv, Line: 3, Raw: "8081"}, "PORT=8081 (dotenv " + dotenv + ":3)"},
+		{"HOST", Origin{Kind: OriginDotenv, File: dotenv, Line: 2, Raw: "example.com"}, "HOST=example.com (dotenv " + dotenv + ":2)"},
+		{"USER", Origin{Kind: OriginEnviron, Raw: "admin"}, "USER=admin (environment)"},
+		{"PASSWORD", Origin{Kind: OriginFile, File: secretFile}, "PASSWORD=[REDACTED] (file " + secretFile + ")"},
+		{"WORKERS", Origin{Kind: OriginSet, Raw: "4"}, "WORKERS=4 (Set)"},
+		{"RETRIES", Origin{}, "RETRIES=3 (default)"},
+	}
+	for _, test := range tests {
+		envVar := evs.Lookup(test.name)
+		if envVar.Origin != test.want {
+			t.Errorf("%s: Origin = %+v, want %+v", test.name, envVar.Origin, test.want)
+		}
+		if s := envVar.String(); s != test.str {
+			t.Errorf("%s: String() = %q, want %q", test.name, s, test.str)
+		}
+	}
+}
+
+func TestOriginRestoredOnFailure(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.Int("PORT", 80)
+	evs.Int("WORKERS", 1)
+	if err := evs.Parse([]string{"PORT=8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := evs.ParseReader(strings.NewReader("PORT=9090\nWORKERS=x\n")); err == nil {
+		t.Fatal("expected error")
+	}
+	if o := evs.Lookup("PORT").Origin; o.Kind != OriginEnviron || o.Raw != "8080" {
+		t.Errorf("failed parse left origin %+v", o)
+	}
+	if err := evs.Set("PORT", "x"); err == nil {
+		t.Fatal("expected error")
+	}
+	if o := evs.Lookup("PORT").Origin; o.Kind != OriginEnviron {
+		t.Errorf("failed Set changed origin to %+v", o)
+	}
+	if err := evs.Reparse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if o := evs.Lookup("PORT").Origin; o != (Origin{}) {
+		t.Errorf("Reparse left origin %+v", o)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import "os"
+
+// OnChange registers fn to be called when Reparse changes the value of the
+// named EnvVar, which must already be defined. The arguments of fn are the
+// old and new values of the EnvVar as given by its Value's String method,
+// masked if the EnvVar is secret. Callbacks run after Reparse has applied
+// every change, in the order they were registered.
+func (evs *EnvVarSet) OnChange(name string, fn func(old, new string)) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	envVar := evs.lookupDefined(name)
+	envVar.onChange = append(envVar.onChange, fn)
+}
+
+// OnChange registers fn to be called when Reparse changes the value of the
+// named EnvVar in the default set.
+func OnChange(name string, fn func(old, new string)) {
+	EnvVars.OnChange(name, fn)
+}
+
+// Reparse parses environment, a complete environment in the form of
+// os.Environ, in place of the one the set was last parsed from: each
+// EnvVar is returned to its default before the parse, so that an env var
+// no longer present no longer applies. Reparse is all or nothing, failing
+// as Parse does and leaving every EnvVar as it was if any value is
+// rejected. Otherwise it calls the OnChange callbacks of the EnvVars whose
+// values changed, in lexicographical order of their names.
+func (evs *EnvVarSet) Reparse(environment []string) error {
+	changes, err := evs.reparse(environment)
+	if err != nil {
+		return err
+	}
+	// The callbacks run without the lock so that they may use the set.
+	for _, c := range changes {
+		c.fn(c.old, c.new)
+	}
+	return nil
+}
+
+// A change records a call due to an OnChange callback.
+type change struct {
+	fn       func(old, new string)
+	old, new string
+}
+
+// reparse reparses environment, returning the calls due to the OnChange
+// callbacks of the EnvVars that changed.
+func (evs *EnvVarSet) reparse(environment []string) ([]change, error) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	before := make(map[*EnvVar]string, len(evs.formal))
+	shown := make(map[*EnvVar]string, len(evs.formal))
+	for _, envVar := range evs.formal {
+		before[envVar] = rawValue(envVar.Value)
+		shown[envVar] = envVar.maskedValue(envVar.Value.String())
+	}
+	if err := evs.parseEntries(splitEnvironment(environment), nil, true, true); err != nil {
+		return nil, err
+	}
+	var changes []change
+	for _, envVar := range sortEnvVars(evs.formal) {
+		if len(envVar.onChange) == 0 || rawValue(envVar.Value) == before[envVar] {
+			continue
+		}
+		now := envVar.maskedValue(envVar.Value.String())
+		for _, fn := range envVar.onChange {
+			changes = append(changes, change{fn, shown[envVar], now})
+		}
+	}
+	return changes, nil
+}
+
+// Reparse reparses the environment of the program into the default set.
+// Like Parse, it exits the program if a value is rejected.
+func Reparse() error {
+	return EnvVars.Reparse(os.Environ())
+}
+
+// reset returns the set's EnvVars to their defaults and marks them unset.
+func (evs *EnvVarSet) reset() {
+	for _, envVar := range evs.formal {
+		envVar.reset()
+		envVar.UsedName = ""
+		envVar.Origin = Origin{}
+	}
+	evs.actual = nil
+}
+
+// rawValue returns the value of v as text, revealing it if v is a Secret,
+// for detecting changes.
+func rawValue(v Value) string {
+	if s, ok := v.(*Secret); ok {
+		return s.Reveal()
+	}
+	return v.String()
+}
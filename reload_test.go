@@ -0,0 +1,101 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestReparse(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	port := evs.Int("PORT", 80)
+	host := evs.String("HOST", "localhost")
+	hosts := evs.StringSlice("PEERS", []string{"a"})
+	var key Secret
+	evs.SecretVar(&key, "KEY", "")
+	var changes []string
+	record := func(name string) func(old, new string) {
+		return func(old, new string) { changes = append(changes, name+":"+old+"->"+new) }
+	}
+	evs.OnChange("PORT", record("PORT"))
+	evs.OnChange("HOST", record("HOST"))
+	evs.OnChange("PEERS", record("PEERS"))
+	evs.OnChange("KEY", record("KEY"))
+
+	if err := evs.Parse([]string{"PORT=8080", "HOST=example.com", "KEY=k1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Parse called callbacks: %v", changes)
+	}
+	if err := evs.Reparse([]string{"PORT=8080", "PEERS=b,c", "KEY=k2"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"HOST:example.com->localhost",
+		"KEY:[REDACTED]->[REDACTED]",
+		"PEERS:a->b,c",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+	if *port != 8080 || *host != "localhost" || len(*hosts) != 2 || key.Reveal() != "k2" {
+		t.Errorf("values after Reparse: %v %v %v %v", *port, *host, *hosts, key.Reveal())
+	}
+	var set []string
+	evs.Visit(func(envVar *EnvVar) { set = append(set, envVar.Name) })
+	if want := []string{"KEY", "PEERS", "PORT"}; !reflect.DeepEqual(set, want) {
+		t.Errorf("set env vars = %v, want %v", set, want)
+	}
+	if evs.Lookup("HOST").UsedName != "" {
+		t.Errorf("HOST still records UsedName %q", evs.Lookup("HOST").UsedName)
+	}
+}
+
+func TestReparseAtomic(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	port := evs.Int("PORT", 80)
+	host := evs.String("HOST", "localhost")
+	evs.Int("WORKERS", 1)
+	evs.Required("WORKERS")
+	called := false
+	evs.OnChange("HOST", func(old, new string) { called = true })
+	if err := evs.Parse([]string{"PORT=8080", "WORKERS=4"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := evs.Reparse([]string{"HOST=example.com", "PORT=x", "WORKERS=4"})
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Name != "PORT" {
+		t.Fatalf("got %v, want *ParseError for PORT", err)
+	}
+	err = evs.Reparse([]string{"HOST=example.com", "PORT=8080"})
+	if !errors.Is(err, ErrRequired) {
+		t.Fatalf("got %v, want required error", err)
+	}
+	if called || *port != 8080 || *host != "localhost" {
+		t.Errorf("failed Reparse applied changes: called=%v port=%v host=%v", called, *port, *host)
+	}
+	if evs.NEnvVar() != 2 || evs.Lookup("PORT").UsedName != "PORT" {
+		t.Errorf("failed Reparse changed set state: %d set", evs.NEnvVar())
+	}
+}
+
+func TestOnChangeUndefinedPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.OnChange("NONE", func(old, new string) {})
+}
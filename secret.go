@@ -0,0 +1,125 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import "strconv"
+
+// redacted replaces the values of secret EnvVars in output.
+const redacted = "[REDACTED]"
+
+// A Secret holds a sensitive value, such as a password, that must not be
+// printed. Its String and GoString methods return a redacted form, so that
+// formatting a Secret with fmt or logging it does not reveal the value;
+// Reveal returns the value itself. *Secret satisfies the Getter interface,
+// with Get returning the Secret.
+type Secret struct {
+	b []byte
+}
+
+// String returns a redacted form of the secret, or the empty string if the
+// secret is empty.
+func (s Secret) String() string {
+	if len(s.b) == 0 {
+		return ""
+	}
+	return redacted
+}
+
+// GoString returns a redacted form of the secret for the %#v verb.
+func (s Secret) GoString() string {
+	return "envvar.Secret(" + strconv.Quote(s.String()) + ")"
+}
+
+// Reveal returns the value of the secret.
+func (s Secret) Reveal() string { return string(s.b) }
+
+// Set sets the value of the secret to v.
+func (s *Secret) Set(v string) error {
+	s.b = []byte(v)
+	return nil
+}
+
+// Get returns the Secret.
+func (s *Secret) Get() interface{} { return *s }
+
+// Zero overwrites the memory holding the value of the secret and empties
+// it. Strings returned by Reveal, and the environment the value was parsed
+// from, are not affected.
+func (s *Secret) Zero() {
+	for i := range s.b {
+		s.b[i] = 0
+	}
+	s.b = nil
+}
+
+// SecretVar defines a Secret EnvVar with specified name, and default value.
+// The argument p points to a Secret variable in which to store the value of the EnvVar.
+// The EnvVar is marked secret; see Redact.
+func (evs *EnvVarSet) SecretVar(p *Secret, name string, value string) {
+	p.Set(value)
+	evs.defineVar(p, name, true)
+}
+
+// SecretVar defines a Secret EnvVar with specified name, and default value.
+// The argument p points to a Secret variable in which to store the value of the EnvVar.
+// The EnvVar is marked secret; see Redact.
+func SecretVar(p *Secret, name string, value string) {
+	EnvVars.SecretVar(p, name, value)
+}
+
+// Redact marks the named EnvVars, which must already be defined, as secret.
+// The values of secret EnvVars are masked in the errors returned by Parse
+// and Set, whose underlying messages are withheld, in the output of
+// PrintDefaults and in the String method of EnvVar.
+func (evs *EnvVarSet) Redact(names ...string) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	for _, name := range names {
+		envVar := evs.lookupDefined(name)
+		envVar.Secret = true
+		envVar.Origin.Raw = ""
+	}
+}
+
+// Redact marks the named EnvVars in the default set as secret.
+func Redact(names ...string) {
+	EnvVars.Redact(names...)
+}
+
+// String returns the EnvVar in the form NAME=value (origin), with the value
+// masked if the EnvVar is secret, for use in dumps of a set's EnvVars.
+func (envVar *EnvVar) String() string {
+	return envVar.Name + "=" + envVar.maskedValue(envVar.Value.String()) + " (" + envVar.Origin.String() + ")"
+}
+
+// maskedValue returns s, a value of envVar, masked if envVar is secret.
+func (envVar *EnvVar) maskedValue(s string) string {
+	if envVar.Secret && s != "" {
+		return redacted
+	}
+	return s
+}
+
+// parseError returns a *ParseError recording that value, given for envVar
+// under name, was rejected with err. If envVar is secret, the value is
+// masked and the message of err, which may quote all or part of the value,
+// is withheld.
+func parseError(envVar *EnvVar, name, value string, err error, file string, line int) *ParseError {
+	if envVar.Secret {
+		err = &redactedError{err}
+		value = redacted
+	}
+	return &ParseError{Name: name, Value: value, Err: err, File: file, Line: line}
+}
+
+// A redactedError withholds the message of an error about a secret value
+// while leaving the error available to errors.Is and errors.As.
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string { return "details redacted" }
+
+func (e *redactedError) Unwrap() error { return e.err }
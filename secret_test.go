@@ -0,0 +1,208 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestSecret(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var password Secret
+	evs.SecretVar(&password, "PASSWORD", "default-pw")
+	if err := evs.Parse([]string{"PASSWORD=hunter22"}); err != nil {
+		t.Fatal(err)
+	}
+	if password.Reveal() != "hunter22" {
+		t.Errorf("Reveal() = %q", password.Reveal())
+	}
+	for _, s := range []string{
+		password.String(),
+		fmt.Sprint(password),
+		fmt.Sprintf("%v %s %q %x %+v %#v", password, password, password, password, &password, password),
+		evs.Lookup("PASSWORD").String(),
+	} {
+		if strings.Contains(s, "hunter22") || !strings.Contains(s, "[REDACTED]") {
+			t.Errorf("secret not redacted: %s", s)
+		}
+	}
+	if got, ok := Get[Secret](evs, "PASSWORD"); !ok || got.Reveal() != "hunter22" {
+		t.Errorf("Get[Secret] = %v, %v", got, ok)
+	}
+	password.Zero()
+	if password.Reveal() != "" || password.String() != "" {
+		t.Errorf("Zero left %q", password.Reveal())
+	}
+}
+
+func TestSecretZeroesMemory(t *testing.T) {
+	var s Secret
+	s.Set("hunter22")
+	v := s // shares the memory of s
+	s.Zero()
+	if v.Reveal() != strings.Repeat("\x00", len("hunter22")) {
+		t.Errorf("memory not zeroed: %q", v.Reveal())
+	}
+}
+
+func TestRedactErrors(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.SetCollectErrors(true)
+	evs.Int("PIN", 0)
+	evs.String("TOKEN", "")
+	evs.Validate("TOKEN", Matches(regexp.MustCompile(`^tok_`)))
+	evs.Redact("PIN", "TOKEN")
+	err := evs.Parse([]string{"PIN=12x4", "TOKEN=s3cr3t-token"})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, leak := range []string{"12x4", "s3cr3t-token"} {
+		if strings.Contains(err.Error(), leak) {
+			t.Errorf("error leaks %q: %v", leak, err)
+		}
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Value != "[REDACTED]" {
+		t.Errorf("ParseError value not redacted: %#v", perr)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("redaction hides the cause: %v", err)
+	}
+	if err := evs.Set("PIN", "99y"); err == nil || strings.Contains(err.Error(), "99y") {
+		t.Errorf("Set error leaks value: %v", err)
+	}
+}
+
+func TestRedactPartialErrors(t *testing.T) {
+	tests := []struct {
+		define func(evs *EnvVarSet)
+		value  string
+		leaks  []string
+		isErr  error
+	}{
+		{func(evs *EnvVarSet) { evs.IntSlice("V", nil) }, "1,hunter2", []string{"hunter2"}, strconv.ErrSyntax},
+		{func(evs *EnvVarSet) { evs.StringToString("V", nil) }, "pw=a,pw=b", []string{"pw"}, nil},
+		{func(evs *EnvVarSet) {
+			evs.StringSlice("V", nil)
+			evs.Validate("V", OneOf("a"))
+		}, "a,hunter2", []string{"hunter2"}, nil},
+		{func(evs *EnvVarSet) {
+			evs.StringSlice("V", nil)
+			evs.Validate("V", Matches(regexp.MustCompile(`^a$`)))
+		}, "a,hunter2", []string{"hunter2"}, nil},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		test.define(evs)
+		evs.Redact("V")
+		err := evs.Parse([]string{"V=" + test.value})
+		if err == nil {
+			t.Errorf("%q: expected error", test.value)
+			continue
+		}
+		for _, leak := range test.leaks {
+			if strings.Contains(err.Error(), leak) {
+				t.Errorf("%q: error leaks %q: %v", test.value, leak, err)
+			}
+		}
+		if test.isErr != nil && !errors.Is(err, test.isErr) {
+			t.Errorf("%q: redaction hides the cause: %v", test.value, err)
+		}
+	}
+}
+
+func TestRedactPrintDefaults(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	var buf bytes.Buffer
+	evs.SetOutput(&buf)
+	var key Secret
+	evs.SecretVar(&key, "API_KEY", "abc123")
+	evs.String("DSN", "postgres://u:pw@db")
+	evs.String("HOST", "localhost")
+	evs.Redact("DSN")
+	evs.PrintDefaults()
+	out := buf.String()
+	for _, leak := range []string{"abc123", "u:pw"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("PrintDefaults leaks %q:\n%s", leak, out)
+		}
+	}
+	for _, want := range []string{"API_KEY  secret  [REDACTED]", "DSN      string  [REDACTED]", `"localhost"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("PrintDefaults output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBindSecret(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	conf := &struct {
+		Key  Secret `env:"KEY"`
+		Pass string `env:"PASS,required,secret"`
+	}{}
+	if err := evs.Bind(conf); err != nil {
+		t.Fatal(err)
+	}
+	if !evs.Lookup("KEY").Secret || !evs.Lookup("PASS").Secret || !evs.Lookup("PASS").Required {
+		t.Error("bound EnvVars not marked secret")
+	}
+	if err := evs.Parse([]string{"KEY=k", "PASS=p"}); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Key.Reveal() != "k" || conf.Pass != "p" {
+		t.Errorf("got %q, %q", conf.Key.Reveal(), conf.Pass)
+	}
+}
+
+func TestValidateSecret(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	var mode, key Secret
+	evs.SecretVar(&mode, "MODE", "")
+	evs.SecretVar(&key, "KEY", "")
+	evs.Validate("MODE", OneOf("a", "b"))
+	evs.Validate("KEY", Matches(regexp.MustCompile(`^sk_`)))
+	if err := evs.Parse([]string{"MODE=a", "KEY=sk_live"}); err != nil {
+		t.Fatal(err)
+	}
+	err := evs.Parse([]string{"MODE=hunter2"})
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("got %v, want error not showing the value", err)
+	}
+}
+
+func TestSecretVarDefinedSecret(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			evs.SecretVar(new(Secret), fmt.Sprint("KEY", i), "hunter2")
+		}
+	}()
+	for visiting := true; visiting; {
+		select {
+		case <-done:
+			visiting = false
+		default:
+		}
+		evs.VisitAll(func(envVar *EnvVar) {
+			if !envVar.Secret {
+				t.Fatalf("%s visited before being marked secret", envVar.Name)
+			}
+		})
+	}
+}
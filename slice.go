@@ -0,0 +1,413 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSeparator separates the elements of list values unless the set
+// has been given another separator.
+const defaultSeparator = ","
+
+// splitList splits s into the elements separated by sep. Whitespace
+// surrounding an element is trimmed. A backslash escapes the following
+// byte and text between double quotes is taken literally, so elements may
+// contain the separator, quotes or significant whitespace. An empty or
+// blank s is an empty list.
+func splitList(s, sep string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var (
+		list    []string
+		elem    []byte
+		keep    int  // length of elem that is quoted or escaped and survives trimming
+		started bool // whether elem has begun, ending the leading whitespace
+		quoted  bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 == len(s) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			elem = append(elem, s[i])
+			keep, started = len(elem), true
+		case c == '"':
+			quoted = !quoted
+			keep, started = len(elem), true
+		case quoted:
+			elem = append(elem, c)
+			keep = len(elem)
+		case strings.HasPrefix(s[i:], sep):
+			list = append(list, trimElem(elem, keep))
+			elem, keep, started = nil, 0, false
+			i += len(sep) - 1
+		case !started && isSpace(c):
+			// skip leading whitespace
+		default:
+			elem = append(elem, c)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quoted string")
+	}
+	return append(list, trimElem(elem, keep)), nil
+}
+
+// trimElem returns elem without trailing whitespace, keeping at least its
+// first keep bytes.
+func trimElem(elem []byte, keep int) string {
+	end := len(elem)
+	for end > keep && isSpace(elem[end-1]) {
+		end--
+	}
+	return string(elem[:end])
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// joinList joins list with sep such that splitList recovers list, quoting
+// the elements that would otherwise be altered by it.
+func joinList(list []string, sep string) string {
+	elems := make([]string, len(list))
+	for i, elem := range list {
+		if elem == "" || strings.Contains(elem, sep) || strings.ContainsAny(elem, `"\`) ||
+			isSpace(elem[0]) || isSpace(elem[len(elem)-1]) {
+			elem = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(elem) + `"`
+		}
+		elems[i] = elem
+	}
+	return strings.Join(elems, sep)
+}
+
+// -- []string Value
+type stringSliceValue struct {
+	p   *[]string
+	sep string
+}
+
+func newStringSliceValue(val []string, p *[]string, sep string) *stringSliceValue {
+	*p = val
+	return &stringSliceValue{p, sep}
+}
+
+func (s *stringSliceValue) Set(val string) error {
+	v, err := splitList(val, s.sep)
+	if err != nil {
+		return err
+	}
+	*s.p = v
+	return nil
+}
+
+func (s *stringSliceValue) Get() interface{} { return *s.p }
+
+func (s *stringSliceValue) save() func() {
+	v := *s.p
+	return func() { *s.p = v }
+}
+
+func (s *stringSliceValue) String() string {
+	if s == nil || s.p == nil {
+		return ""
+	}
+	return joinList(*s.p, s.sep)
+}
+
+// -- []int Value
+type intSliceValue struct {
+	p   *[]int
+	sep string
+}
+
+func newIntSliceValue(val []int, p *[]int, sep string) *intSliceValue {
+	*p = val
+	return &intSliceValue{p, sep}
+}
+
+func (s *intSliceValue) Set(val string) error {
+	elems, err := splitList(val, s.sep)
+	if err != nil {
+		return err
+	}
+	var v []int
+	for _, elem := range elems {
+		i, err := strconv.ParseInt(elem, 0, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		v = append(v, int(i))
+	}
+	*s.p = v
+	return nil
+}
+
+func (s *intSliceValue) Get() interface{} { return *s.p }
+
+func (s *intSliceValue) save() func() {
+	v := *s.p
+	return func() { *s.p = v }
+}
+
+func (s *intSliceValue) String() string {
+	if s == nil || s.p == nil {
+		return ""
+	}
+	elems := make([]string, len(*s.p))
+	for i, v := range *s.p {
+		elems[i] = strconv.Itoa(v)
+	}
+	return joinList(elems, s.sep)
+}
+
+// -- []float64 Value
+type float64SliceValue struct {
+	p   *[]float64
+	sep string
+}
+
+func newFloat64SliceValue(val []float64, p *[]float64, sep string) *float64SliceValue {
+	*p = val
+	return &float64SliceValue{p, sep}
+}
+
+func (s *float64SliceValue) Set(val string) error {
+	elems, err := splitList(val, s.sep)
+	if err != nil {
+		return err
+	}
+	var v []float64
+	for _, elem := range elems {
+		f, err := strconv.ParseFloat(elem, 64)
+		if err != nil {
+			return err
+		}
+		v = append(v, f)
+	}
+	*s.p = v
+	return nil
+}
+
+func (s *float64SliceValue) Get() interface{} { return *s.p }
+
+func (s *float64SliceValue) save() func() {
+	v := *s.p
+	return func() { *s.p = v }
+}
+
+func (s *float64SliceValue) String() string {
+	if s == nil || s.p == nil {
+		return ""
+	}
+	elems := make([]string, len(*s.p))
+	for i, v := range *s.p {
+		elems[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return joinList(elems, s.sep)
+}
+
+// -- []time.Duration Value
+type durationSliceValue struct {
+	p   *[]time.Duration
+	sep string
+}
+
+func newDurationSliceValue(val []time.Duration, p *[]time.Duration, sep string) *durationSliceValue {
+	*p = val
+	return &durationSliceValue{p, sep}
+}
+
+func (s *durationSliceValue) Set(val string) error {
+	elems, err := splitList(val, s.sep)
+	if err != nil {
+		return err
+	}
+	var v []time.Duration
+	for _, elem := range elems {
+		d, err := time.ParseDuration(elem)
+		if err != nil {
+			return err
+		}
+		v = append(v, d)
+	}
+	*s.p = v
+	return nil
+}
+
+func (s *durationSliceValue) Get() interface{} { return *s.p }
+
+func (s *durationSliceValue) save() func() {
+	v := *s.p
+	return func() { *s.p = v }
+}
+
+func (s *durationSliceValue) String() string {
+	if s == nil || s.p == nil {
+		return ""
+	}
+	elems := make([]string, len(*s.p))
+	for i, v := range *s.p {
+		elems[i] = v.String()
+	}
+	return joinList(elems, s.sep)
+}
+
+// Separator returns the separator used between the elements of list
+// EnvVars defined in the set.
+func (evs *EnvVarSet) Separator() string {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	if evs.separator == "" {
+		return defaultSeparator
+	}
+	return evs.separator
+}
+
+// SetSeparator sets the separator used between the elements of list
+// EnvVars subsequently defined in the set. The default is a comma.
+// Elements containing the separator may be double quoted or have the
+// separator escaped with a backslash.
+func (evs *EnvVarSet) SetSeparator(sep string) {
+	if sep == "" {
+		panic("envvar: empty separator")
+	}
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	evs.separator = sep
+}
+
+// StringSliceVar defines a []string EnvVar with specified name, and default value.
+// The argument p points to a []string variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list separated by the set's separator.
+func (evs *EnvVarSet) StringSliceVar(p *[]string, name string, value []string) {
+	evs.Var(newStringSliceValue(value, p, evs.Separator()), name)
+}
+
+// StringSliceVar defines a []string EnvVar with specified name, and default value.
+// The argument p points to a []string variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list separated by the default set's separator.
+func StringSliceVar(p *[]string, name string, value []string) {
+	EnvVars.StringSliceVar(p, name, value)
+}
+
+// StringSlice defines a []string EnvVar with specified name, and default value.
+// The return value is the address of a []string variable that stores the value of the EnvVar.
+// The EnvVar accepts a list separated by the set's separator.
+func (evs *EnvVarSet) StringSlice(name string, value []string) *[]string {
+	p := new([]string)
+	evs.StringSliceVar(p, name, value)
+	return p
+}
+
+// StringSlice defines a []string EnvVar with specified name, and default value.
+// The return value is the address of a []string variable that stores the value of the EnvVar.
+// The EnvVar accepts a list separated by the default set's separator.
+func StringSlice(name string, value []string) *[]string {
+	return EnvVars.StringSlice(name, value)
+}
+
+// IntSliceVar defines a []int EnvVar with specified name, and default value.
+// The argument p points to a []int variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list separated by the set's separator.
+func (evs *EnvVarSet) IntSliceVar(p *[]int, name string, value []int) {
+	evs.Var(newIntSliceValue(value, p, evs.Separator()), name)
+}
+
+// IntSliceVar defines a []int EnvVar with specified name, and default value.
+// The argument p points to a []int variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list separated by the default set's separator.
+func IntSliceVar(p *[]int, name string, value []int) {
+	EnvVars.IntSliceVar(p, name, value)
+}
+
+// IntSlice defines a []int EnvVar with specified name, and default value.
+// The return value is the address of a []int variable that stores the value of the EnvVar.
+// The EnvVar accepts a list separated by the set's separator.
+func (evs *EnvVarSet) IntSlice(name string, value []int) *[]int {
+	p := new([]int)
+	evs.IntSliceVar(p, name, value)
+	return p
+}
+
+// IntSlice defines a []int EnvVar with specified name, and default value.
+// The return value is the address of a []int variable that stores the value of the EnvVar.
+// The EnvVar accepts a list separated by the default set's separator.
+func IntSlice(name string, value []int) *[]int {
+	return EnvVars.IntSlice(name, value)
+}
+
+// Float64SliceVar defines a []float64 EnvVar with specified name, and default value.
+// The argument p points to a []float64 variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list separated by the set's separator.
+func (evs *EnvVarSet) Float64SliceVar(p *[]float64, name string, value []float64) {
+	evs.Var(newFloat64SliceValue(value, p, evs.Separator()), name)
+}
+
+// Float64SliceVar defines a []float64 EnvVar with specified name, and default value.
+// The argument p points to a []float64 variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list separated by the default set's separator.
+func Float64SliceVar(p *[]float64, name string, value []float64) {
+	EnvVars.Float64SliceVar(p, name, value)
+}
+
+// Float64Slice defines a []float64 EnvVar with specified name, and default value.
+// The return value is the address of a []float64 variable that stores the value of the EnvVar.
+// The EnvVar accepts a list separated by the set's separator.
+func (evs *EnvVarSet) Float64Slice(name string, value []float64) *[]float64 {
+	p := new([]float64)
+	evs.Float64SliceVar(p, name, value)
+	return p
+}
+
+// Float64Slice defines a []float64 EnvVar with specified name, and default value.
+// The return value is the address of a []float64 variable that stores the value of the EnvVar.
+// The EnvVar accepts a list separated by the default set's separator.
+func Float64Slice(name string, value []float64) *[]float64 {
+	return EnvVars.Float64Slice(name, value)
+}
+
+// DurationSliceVar defines a []time.Duration EnvVar with specified name, and default value.
+// The argument p points to a []time.Duration variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list, separated by the set's separator, of values
+// acceptable to time.ParseDuration.
+func (evs *EnvVarSet) DurationSliceVar(p *[]time.Duration, name string, value []time.Duration) {
+	evs.Var(newDurationSliceValue(value, p, evs.Separator()), name)
+}
+
+// DurationSliceVar defines a []time.Duration EnvVar with specified name, and default value.
+// The argument p points to a []time.Duration variable in which to store the value of the EnvVar.
+// The EnvVar accepts a list, separated by the default set's separator, of values
+// acceptable to time.ParseDuration.
+func DurationSliceVar(p *[]time.Duration, name string, value []time.Duration) {
+	EnvVars.DurationSliceVar(p, name, value)
+}
+
+// DurationSlice defines a []time.Duration EnvVar with specified name, and default value.
+// The return value is the address of a []time.Duration variable that stores the value of the EnvVar.
+// The EnvVar accepts a list, separated by the set's separator, of values
+// acceptable to time.ParseDuration.
+func (evs *EnvVarSet) DurationSlice(name string, value []time.Duration) *[]time.Duration {
+	p := new([]time.Duration)
+	evs.DurationSliceVar(p, name, value)
+	return p
+}
+
+// DurationSlice defines a []time.Duration EnvVar with specified name, and default value.
+// The return value is the address of a []time.Duration variable that stores the value of the EnvVar.
+// The EnvVar accepts a list, separated by the default set's separator, of values
+// acceptable to time.ParseDuration.
+func DurationSlice(name string, value []time.Duration) *[]time.Duration {
+	return EnvVars.DurationSlice(name, value)
+}
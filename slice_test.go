@@ -0,0 +1,99 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestStringSlice(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"a", []string{"a"}},
+		{"a,b,c", []string{"a", "b", "c"}},
+		{" a , b ,c ", []string{"a", "b", "c"}},
+		{"a,,b", []string{"a", "", "b"}},
+		{`"a,b",c`, []string{"a,b", "c"}},
+		{`a\,b,c`, []string{"a,b", "c"}},
+		{`" a ",b`, []string{" a ", "b"}},
+		{`say "hi",x`, []string{"say hi", "x"}},
+		{`a\"b`, []string{`a"b`}},
+		{`""`, []string{""}},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		s := evs.StringSlice("S", nil)
+		if err := evs.Parse([]string{"S=" + test.in}); err != nil {
+			t.Errorf("Parse(%q): %v", test.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(*s, test.want) {
+			t.Errorf("Parse(%q) = %q, want %q", test.in, *s, test.want)
+		}
+		// String must round-trip.
+		str := evs.Lookup("S").Value.String()
+		if err := evs.Set("S", str); err != nil || !reflect.DeepEqual(*s, test.want) {
+			t.Errorf("round trip of %q via %q = %q, %v", test.in, str, *s, err)
+		}
+	}
+}
+
+func TestStringSliceErrors(t *testing.T) {
+	for _, in := range []string{`"a,b`, `a\`} {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.StringSlice("S", nil)
+		if err := evs.Set("S", in); err == nil {
+			t.Errorf("Set(%q) succeeded", in)
+		}
+	}
+}
+
+func TestSliceTypes(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetSeparator(";")
+	ints := evs.IntSlice("INTS", []int{1})
+	floats := evs.Float64Slice("FLOATS", nil)
+	durations := evs.DurationSlice("DURATIONS", nil)
+	strs := evs.StringSlice("STRINGS", nil)
+	args := []string{
+		"INTS=1; 0x10; -3",
+		"FLOATS=1.5;2e3",
+		"DURATIONS=1s; 2m",
+		"STRINGS=a,b;c",
+	}
+	if err := evs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*ints, []int{1, 16, -3}) {
+		t.Errorf("ints = %v", *ints)
+	}
+	if !reflect.DeepEqual(*floats, []float64{1.5, 2e3}) {
+		t.Errorf("floats = %v", *floats)
+	}
+	if !reflect.DeepEqual(*durations, []time.Duration{time.Second, 2 * time.Minute}) {
+		t.Errorf("durations = %v", *durations)
+	}
+	if !reflect.DeepEqual(*strs, []string{"a,b", "c"}) {
+		t.Errorf("strings = %q", *strs)
+	}
+	if s := evs.Lookup("INTS").Value.String(); s != "1;16;-3" {
+		t.Errorf("INTS String() = %q", s)
+	}
+	g := evs.Lookup("DURATIONS").Value.(Getter)
+	if _, ok := g.Get().([]time.Duration); !ok {
+		t.Errorf("DURATIONS Get() returned %T", g.Get())
+	}
+	if err := evs.Set("INTS", "1;x"); err == nil {
+		t.Error("Set(INTS, 1;x) succeeded")
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+// A Snapshot is an immutable copy of the values of a set's EnvVars taken at
+// one moment. Unlike the variables bound to the EnvVars, a Snapshot may be
+// read while the set is being modified. Names are given without the set's
+// prefix.
+type Snapshot struct {
+	prefix string
+	vars   map[string]snapshotVar
+}
+
+// A snapshotVar is the state of an EnvVar recorded by a Snapshot.
+type snapshotVar struct {
+	value interface{}
+	text  string
+	set   bool
+}
+
+// Snapshot returns a Snapshot of the values of the set's EnvVars. For the
+// default set, call EnvVars.Snapshot.
+func (evs *EnvVarSet) Snapshot() *Snapshot {
+	evs.mu.RLock()
+	defer evs.mu.RUnlock()
+	s := &Snapshot{prefix: evs.prefix, vars: make(map[string]snapshotVar, len(evs.formal))}
+	for name, envVar := range evs.formal {
+		v := snapshotVar{text: envVar.maskedValue(envVar.Value.String()), set: evs.actual[name] != nil}
+		if g, ok := envVar.Value.(Getter); ok {
+			v.value = g.Get()
+		} else {
+			v.value = v.text
+		}
+		s.vars[name] = v
+	}
+	return s
+}
+
+// Value returns the value of the named EnvVar as returned by the Get method
+// of its Value, or by its String method if the Value does not satisfy
+// Getter. The boolean is false if the EnvVar was not defined.
+func (s *Snapshot) Value(name string) (interface{}, bool) {
+	v, ok := s.vars[s.prefix+name]
+	return v.value, ok
+}
+
+// Text returns the value of the named EnvVar as given by the String method
+// of its Value, masked if the EnvVar is secret. The boolean is false if the
+// EnvVar was not defined.
+func (s *Snapshot) Text(name string) (string, bool) {
+	v, ok := s.vars[s.prefix+name]
+	return v.text, ok
+}
+
+// IsSet reports whether the named EnvVar had been set.
+func (s *Snapshot) IsSet(name string) bool {
+	return s.vars[s.prefix+name].set
+}
@@ -0,0 +1,111 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestSnapshot(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetPrefix("APP_")
+	evs.Int("PORT", 80)
+	evs.StringSlice("HOSTS", []string{"a"})
+	var key Secret
+	evs.SecretVar(&key, "KEY", "")
+	if err := evs.Parse([]string{"APP_PORT=8080", "APP_KEY=k"}); err != nil {
+		t.Fatal(err)
+	}
+	snap := evs.Snapshot()
+	if err := evs.Set("PORT", "9090"); err != nil {
+		t.Fatal(err)
+	}
+	if err := evs.Set("HOSTS", "b,c"); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := snap.Value("PORT"); !ok || v != 8080 {
+		t.Errorf("PORT = %v, %v; want 8080 as of the snapshot", v, ok)
+	}
+	if s, ok := snap.Text("HOSTS"); !ok || s != "a" {
+		t.Errorf("HOSTS = %q, %v", s, ok)
+	}
+	if s, _ := snap.Text("KEY"); s != "[REDACTED]" {
+		t.Errorf("KEY shown as %q", s)
+	}
+	if !snap.IsSet("PORT") || snap.IsSet("HOSTS") {
+		t.Error("IsSet does not reflect the snapshot")
+	}
+	if _, ok := snap.Value("NONE"); ok {
+		t.Error("Value of undefined EnvVar reported as present")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.Int("PORT", 80)
+	evs.String("HOST", "localhost")
+	evs.Alias("PORT", "LISTEN_PORT")
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				fn(i)
+			}
+		}()
+	}
+	run(func(i int) { evs.Set("PORT", strconv.Itoa(i)) })
+	run(func(i int) { evs.Set("HOST", fmt.Sprint("host", i)) })
+	run(func(i int) { evs.Reparse([]string{"PORT=" + strconv.Itoa(i)}) })
+	run(func(i int) { evs.Var(new(userVar), fmt.Sprint("USER", i)) })
+	run(func(i int) {
+		envVar := evs.Lookup("PORT")
+		_ = envVar.Name + envVar.UsedName + envVar.Origin.String() + strings.Join(envVar.Aliases, ",")
+	})
+	run(func(i int) { Get[int](evs, "PORT") })
+	run(func(i int) { evs.Visit(func(envVar *EnvVar) { _ = envVar.UsedName + envVar.Origin.String() }) })
+	run(func(i int) {
+		evs.VisitAll(func(envVar *EnvVar) {
+			_ = envVar.UsedName + envVar.Origin.String() + strings.Join(envVar.Aliases, ",")
+		})
+	})
+	run(func(i int) { evs.SetPrefix(fmt.Sprint("P", i%2, "_")) })
+	run(func(i int) {
+		evs.SetOutput(ioutil.Discard)
+		evs.SetCollectErrors(i%2 == 0)
+		evs.SetStrict("")
+		evs.SetExpand(false)
+		evs.SetFileSuffix("_FILE")
+		evs.SetSeparator(",")
+	})
+	run(func(i int) { evs.Parse([]string{"HOST=h" + strconv.Itoa(i)}) })
+	run(func(i int) { evs.PrintDefaults() })
+	run(func(i int) { evs.Subset(fmt.Sprint("SUB", i, "_")) })
+	run(func(i int) {
+		snap := evs.Snapshot()
+		snap.Text("HOST")
+		snap.Value("PORT")
+	})
+	run(func(i int) { evs.NEnvVar() })
+	wg.Wait()
+	if n := len(allNames(evs)); n != 102 {
+		t.Errorf("%d EnvVars defined, want 102", n)
+	}
+}
+
+func allNames(evs *EnvVarSet) []string {
+	var names []string
+	evs.VisitAll(func(envVar *EnvVar) { names = append(names, envVar.Name) })
+	return names
+}
@@ -0,0 +1,227 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+)
+
+// A Source supplies env var definitions to ParseSources. The package
+// provides sources for the environment of the program, in-memory maps,
+// dotenv files and JSON files; other formats, such as YAML, can be
+// supported by implementing Source, for instance by decoding a file into a
+// map and returning it from Load.
+type Source interface {
+	// Name describes the source in the Origin of the values it supplies.
+	Name() string
+	// Load returns the definitions of the source, keyed by env var name.
+	Load() (map[string]string, error)
+}
+
+// An entrySource is a Source whose definitions have an order and locations
+// worth keeping, such as those of a dotenv file.
+type entrySource interface {
+	Source
+	entries() ([]entry, error)
+}
+
+// EnvironSource returns a Source supplying the environment of the program,
+// as given by os.Environ when the source is loaded.
+func EnvironSource() Source { return environSource{} }
+
+type environSource struct{}
+
+func (environSource) Name() string { return "environment" }
+
+func (s environSource) Load() (map[string]string, error) { return entryMap(s.entries()) }
+
+func (s environSource) entries() ([]entry, error) {
+	entries := splitEnvironment(os.Environ())
+	for i := range entries {
+		entries[i].source = s.Name()
+	}
+	return entries, nil
+}
+
+// MapSource returns a Source, with the given name, supplying the
+// definitions of m.
+func MapSource(name string, m map[string]string) Source { return &mapSource{name, m} }
+
+type mapSource struct {
+	name string
+	m    map[string]string
+}
+
+func (s *mapSource) Name() string { return s.name }
+
+func (s *mapSource) Load() (map[string]string, error) { return s.m, nil }
+
+// DotenvSource returns a Source supplying the definitions of the named
+// dotenv file. See ParseReader for the syntax of the file.
+func DotenvSource(filename string) Source { return dotenvSource(filename) }
+
+type dotenvSource string
+
+func (s dotenvSource) Name() string { return string(s) }
+
+func (s dotenvSource) Load() (map[string]string, error) { return entryMap(s.entries()) }
+
+func (s dotenvSource) entries() ([]entry, error) {
+	f, err := os.Open(string(s))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := readDotenv(f, string(s))
+	for i := range entries {
+		entries[i].source = s.Name()
+	}
+	return entries, err
+}
+
+// JSONSource returns a Source supplying the definitions of the named JSON
+// file, which holds an object mapping env var names to strings, numbers or
+// booleans. Numbers are given as written in the file.
+func JSONSource(filename string) Source { return jsonSource(filename) }
+
+type jsonSource string
+
+func (s jsonSource) Name() string { return string(s) }
+
+func (s jsonSource) Load() (map[string]string, error) {
+	b, err := ioutil.ReadFile(string(s))
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", s, err)
+	}
+	m := make(map[string]string, len(raw))
+	for name, v := range raw {
+		switch v := v.(type) {
+		case string:
+			m[name] = v
+		case json.Number:
+			m[name] = v.String()
+		case bool:
+			m[name] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("%s: value of %s is not a string, number or boolean", s, name)
+		}
+	}
+	return m, nil
+}
+
+// entryMap returns the definitions of entries as a map, in which later
+// definitions of a name replace earlier ones.
+func entryMap(entries []entry, err error) (map[string]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(entries))
+	for _, e := range entries {
+		m[e.name] = e.value
+	}
+	return m, nil
+}
+
+// loadEntries returns the definitions of s, with the last definition of
+// each name only.
+func loadEntries(s Source) ([]entry, error) {
+	var entries []entry
+	if es, ok := s.(entrySource); ok {
+		var err error
+		if entries, err = es.entries(); err != nil {
+			return nil, err
+		}
+	} else {
+		m, err := s.Load()
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range m {
+			entries = append(entries, entry{name: name, value: value, kind: OriginSource, source: s.Name()})
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	}
+	last := make(map[string]int, len(entries))
+	for i, e := range entries {
+		last[e.name] = i
+	}
+	deduped := entries[:0]
+	for i, e := range entries {
+		if last[e.name] == i {
+			deduped = append(deduped, e)
+		}
+	}
+	return deduped, nil
+}
+
+// ParseSources parses the env vars supplied by sources, which are given in
+// decreasing order of precedence: a source that supplies an EnvVar, under
+// any of its names or through the file suffix, overrides the sources that
+// follow it for that EnvVar, and a later definition of a name in a source
+// overrides an earlier one. The definitions are then parsed as by Parse,
+// including the check for required EnvVars, with the Origin of each value
+// naming the source that supplied it. If a source supplies an EnvVar under
+// several of its names, the EnvVar's Name and aliases take precedence as
+// described for Alias.
+//
+// To give the environment precedence over a dotenv file, for instance:
+//
+//	evs.ParseSources(envvar.EnvironSource(), envvar.DotenvSource(".env"))
+func (evs *EnvVarSet) ParseSources(sources ...Source) error {
+	loaded := make([][]entry, len(sources))
+	for i, s := range sources {
+		entries, err := loadEntries(s)
+		if err != nil {
+			evs.mu.Lock()
+			defer evs.mu.Unlock()
+			evs.parsed = true
+			return evs.handleError(evs.fail(err))
+		}
+		loaded[i] = entries
+	}
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	var entries []entry
+	claimed := make(map[*EnvVar]bool) // EnvVars supplied by an earlier source
+	seen := make(map[string]bool)     // names of no EnvVar already supplied
+	for _, source := range loaded {
+		supplied := make(map[*EnvVar]bool)
+		for _, e := range source {
+			envVar := evs.resolve(e.name)
+			if envVar == nil {
+				envVar, _ = evs.fileEnvVar(e.name)
+			}
+			switch {
+			case envVar != nil && !claimed[envVar]:
+				supplied[envVar] = true
+				entries = append(entries, e)
+			case envVar == nil && !seen[e.name]:
+				seen[e.name] = true
+				entries = append(entries, e)
+			}
+		}
+		for envVar := range supplied {
+			claimed[envVar] = true
+		}
+	}
+	return evs.parseEntries(entries, nil, true, false)
+}
+
+// ParseSources parses the env vars supplied by sources, in decreasing
+// order of precedence, into the default set.
+func ParseSources(sources ...Source) error {
+	return EnvVars.ParseSources(sources...)
+}
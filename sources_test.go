@@ -0,0 +1,164 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+// countingVar counts the calls of its Set method.
+type countingVar struct {
+	value string
+	sets  int
+}
+
+func (c *countingVar) String() string { return c.value }
+
+func (c *countingVar) Set(s string) error {
+	c.value = s
+	c.sets++
+	return nil
+}
+
+func TestParseSources(t *testing.T) {
+	dir := t.TempDir()
+	dotenv, config := filepath.Join(dir, ".env"), filepath.Join(dir, "config.json")
+	const src = "ENVVAR_TEST_ADDR=dotenv.example.com\nENVVAR_TEST_PORT=7070\nENVVAR_TEST_NAME=first\nENVVAR_TEST_NAME=second\n"
+	if err := ioutil.WriteFile(dotenv, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	const js = `{"ENVVAR_TEST_PORT": 6060, "ENVVAR_TEST_DEBUG": true, "ENVVAR_TEST_WORKERS": "2", "ENVVAR_TEST_NAME": "json"}`
+	if err := ioutil.WriteFile(config, []byte(js), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// The names are prefixed so that the environment of the test cannot
+	// supply them.
+	t.Setenv("ENVVAR_TEST_HOST", "env.example.com")
+
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetPrefix("ENVVAR_TEST_")
+	host := evs.String("HOST", "localhost")
+	evs.Alias("HOST", "ADDR")
+	port := evs.Int("PORT", 80)
+	debug := evs.Bool("DEBUG", false)
+	workers := evs.Int("WORKERS", 1)
+	retries := evs.Int("RETRIES", 0)
+	name := &countingVar{}
+	evs.Var(name, "NAME")
+
+	err := evs.ParseSources(
+		EnvironSource(),
+		DotenvSource(dotenv),
+		JSONSource(config),
+		MapSource("defaults", map[string]string{"ENVVAR_TEST_RETRIES": "3", "ENVVAR_TEST_PORT": "5050"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *host != "env.example.com" || *port != 7070 || !*debug || *workers != 2 || *retries != 3 {
+		t.Errorf("got host=%v port=%v debug=%v workers=%v retries=%v", *host, *port, *debug, *workers, *retries)
+	}
+	if name.value != "second" || name.sets != 1 {
+		t.Errorf("NAME = %q after %d calls of Set, want second after 1", name.value, name.sets)
+	}
+	origins := map[string]string{
+		"HOST":    "environment",
+		"PORT":    "dotenv " + dotenv + ":2",
+		"NAME":    "dotenv " + dotenv + ":4",
+		"DEBUG":   "source " + config,
+		"RETRIES": "source defaults",
+	}
+	for n, want := range origins {
+		if o := evs.Lookup(n).Origin; o.String() != want {
+			t.Errorf("%s: origin %v, want %s", n, o, want)
+		}
+	}
+	if o := evs.Lookup("HOST").Origin; o.Source != "environment" || o.Raw != "env.example.com" {
+		t.Errorf("HOST: origin %+v", o)
+	}
+	if u := evs.Lookup("HOST").UsedName; u != "ENVVAR_TEST_HOST" {
+		t.Errorf("HOST set through %s", u)
+	}
+}
+
+func TestParseSourcesErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"nested.json": `{"A": {"B": "c"}}`,
+		"bad.json":    `{"A": `,
+		"bad.env":     "A\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		source Source
+		want   string
+	}{
+		{JSONSource(filepath.Join(dir, "nested.json")), "value of A is not a string, number or boolean"},
+		{JSONSource(filepath.Join(dir, "bad.json")), "bad.json: unexpected EOF"},
+		{JSONSource(filepath.Join(dir, "missing.json")), "no such file"},
+		{DotenvSource(filepath.Join(dir, "bad.env")), "bad.env:1: missing = after A"},
+		{MapSource("m", map[string]string{"A": "x"}), `invalid value "x" for env var A`},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		a := evs.Int("A", 1)
+		err := evs.ParseSources(MapSource("first", nil), test.source)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got %v, want error containing %q", test.source.Name(), err, test.want)
+		}
+		if *a != 1 || !evs.Parsed() {
+			t.Errorf("%s: A = %d, parsed = %v after error", test.source.Name(), *a, evs.Parsed())
+		}
+	}
+}
+
+func TestParseSourcesRequired(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.String("TOKEN", "")
+	evs.Required("TOKEN")
+	if err := evs.ParseSources(MapSource("m", map[string]string{"OTHER": "x"})); !errors.Is(err, ErrRequired) {
+		t.Errorf("got %v, want required error", err)
+	}
+	if err := evs.ParseSources(MapSource("m", map[string]string{"TOKEN": "x"})); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseSourcesPrecedence(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.SetFileSuffix("_FILE")
+	host := evs.String("DATABASE_HOST", "")
+	evs.Alias("DATABASE_HOST", "DB_HOST")
+	password := evs.String("DB_PASSWORD", "")
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := ioutil.WriteFile(secret, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err := evs.ParseSources(
+		MapSource("env", map[string]string{"DB_HOST": "prod", "DB_PASSWORD_FILE": secret}),
+		MapSource("defaults", map[string]string{"DATABASE_HOST": "dev", "DB_PASSWORD": "default"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *host != "prod" || *password != "from-file" {
+		t.Errorf("got host=%q password=%q, want prod and from-file", *host, *password)
+	}
+	if u := evs.Lookup("DATABASE_HOST").UsedName; u != "DB_HOST" {
+		t.Errorf("DATABASE_HOST set through %s", u)
+	}
+}
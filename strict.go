@@ -0,0 +1,105 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import "strings"
+
+// SetStrict sets the prefix under which the set rejects unknown env vars:
+// Parse fails with an *UnknownError for any env var whose name begins with
+// prefix but which names no EnvVar of the set, suggesting a close match if
+// there is one. An empty prefix, the default, disables the check.
+func (evs *EnvVarSet) SetStrict(prefix string) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	evs.strict = prefix
+}
+
+// isStrict reports whether name is subject to the set's strict check.
+func (evs *EnvVarSet) isStrict(name string) bool {
+	return evs.strict != "" && strings.HasPrefix(name, evs.strict) && !evs.inSubset(name)
+}
+
+// inSubset reports whether name names an EnvVar, directly or through the
+// file suffix, of one of the set's subsets or of theirs.
+func (evs *EnvVarSet) inSubset(name string) bool {
+	for _, sub := range evs.subsets {
+		sub.mu.RLock()
+		envVar, _ := sub.fileEnvVar(name)
+		ok := envVar != nil || sub.resolve(name) != nil || sub.inSubset(name)
+		sub.mu.RUnlock()
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSuggestDistance is the largest edit distance between an unknown name
+// and a name suggested in its place.
+const maxSuggestDistance = 2
+
+// suggest returns the name of the set that is closest to name, or "" if
+// none is close.
+func (evs *EnvVarSet) suggest(name string) string {
+	var names []string
+	for n := range evs.formal {
+		names = append(names, n)
+	}
+	for n := range evs.aliases {
+		names = append(names, n)
+	}
+	best, bestDist := "", maxSuggestDistance+1
+	consider := func(n string) {
+		d := editDistance(name, n)
+		if d < bestDist || d == bestDist && n < best {
+			best, bestDist = n, d
+		}
+	}
+	for _, n := range names {
+		consider(n)
+		if evs.fileSuffix != "" {
+			consider(n + evs.fileSuffix)
+		}
+	}
+	if bestDist >= len(name) {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: the number of insertions, deletions, substitutions and transpositions
+// of adjacent bytes needed to turn one into the other.
+func editDistance(a, b string) int {
+	// d[i][j] is the distance between a[:i] and b[:j].
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, minInt(d[i][j-1]+1, d[i-1][j-1]+cost))
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestStrict(t *testing.T) {
+	tests := []struct {
+		name, suggestion string
+	}{
+		{"APP_PROT", "APP_PORT"},
+		{"APP_PORTS", "APP_PORT"},
+		{"APP_HOTS", "APP_HOST"},
+		{"APP_DBHOTS", "APP_DBHOST"},
+		{"APP_PASWORD_FILE", "APP_PASSWORD_FILE"},
+		{"APP_SOMETHING_ELSE", ""},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		evs.SetPrefix("APP_")
+		evs.SetStrict("APP_")
+		evs.SetFileSuffix("_FILE")
+		evs.Int("PORT", 80)
+		evs.String("HOST", "")
+		evs.String("PASSWORD", "")
+		evs.String("DATABASE_HOST", "")
+		evs.Alias("DATABASE_HOST", "DBHOST")
+		err := evs.Parse([]string{"APP_PORT=1", test.name + "=x", "OTHER=y"})
+		var uerr *UnknownError
+		if !errors.As(err, &uerr) || uerr.Name != test.name || uerr.Suggestion != test.suggestion {
+			t.Errorf("%s: want *UnknownError suggesting %q, got %#v", test.name, test.suggestion, err)
+		}
+		if !errors.Is(err, ErrUnknown) {
+			t.Errorf("%s: errors.Is(err, ErrUnknown) = false", test.name)
+		}
+	}
+}
+
+func TestStrictMessage(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.SetStrict("APP_")
+	evs.Int("APP_PORT", 80)
+	err := evs.ParseReader(strings.NewReader("APP_PORT=1\nAPP_PROT=80\n"))
+	want := "line 2: no such environment variable APP_PROT; did you mean APP_PORT?"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
+
+func TestStrictAllowsKnown(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetStrict("APP_")
+	evs.SetFileSuffix("_FILE")
+	evs.Int("APP_PORT", 80)
+	evs.Alias("APP_PORT", "APP_LISTEN_PORT")
+	if err := evs.Parse([]string{"APP_LISTEN_PORT=1", "HOME=/", "APPLE=1"}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStrictSubset(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	evs.SetPrefix("BILLING_")
+	evs.SetStrict("BILLING_")
+	evs.Int("PORT", 80)
+	db := evs.Subset("DB_")
+	db.String("URL", "")
+	env := []string{"BILLING_PORT=1", "BILLING_DB_URL=postgres://db"}
+	if err := evs.Parse(env); err != nil {
+		t.Errorf("set: %v", err)
+	}
+	if err := db.Parse(env); err != nil {
+		t.Errorf("subset: %v", err)
+	}
+
+	var uerr *UnknownError
+	err := evs.Parse(append(env, "BILLING_PROT=1"))
+	if !errors.As(err, &uerr) || uerr.Name != "BILLING_PROT" {
+		t.Errorf("set: want *UnknownError for BILLING_PROT, got %v", err)
+	}
+	err = db.Parse(append(env, "BILLING_DB_ULR=x"))
+	if !errors.As(err, &uerr) || uerr.Name != "BILLING_DB_ULR" {
+		t.Errorf("subset: want *UnknownError for BILLING_DB_ULR, got %v", err)
+	}
+}
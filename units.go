@@ -0,0 +1,388 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// byteUnits maps the case-folded byte size suffixes to their multipliers.
+var byteUnits = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"k":   1e3,
+	"m":   1e6,
+	"g":   1e9,
+	"t":   1e12,
+	"p":   1e15,
+	"e":   1e18,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"eb":  1e18,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+	"eib": 1 << 60,
+}
+
+// parseByteSize returns the number of bytes in s, a non-negative decimal
+// number optionally followed by an SI or IEC suffix such as kB or MiB, or by
+// the first letter of an SI suffix such as k or M.
+func parseByteSize(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.TrimSpace(s[i:])
+	mult, ok := byteUnits[strings.ToLower(unit)]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", unit)
+	}
+	r, ok := new(big.Rat).SetString(num)
+	if num == "" || strings.Count(num, ".") > 1 || !ok {
+		return 0, errors.New("invalid number of bytes")
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).SetUint64(mult)))
+	if !r.IsInt() {
+		return 0, errors.New("not a whole number of bytes")
+	}
+	if !r.Num().IsUint64() {
+		return 0, strconv.ErrRange
+	}
+	return r.Num().Uint64(), nil
+}
+
+// formatByteSize formats n with the largest suffix that represents it
+// exactly, preferring IEC suffixes to SI suffixes of the same size.
+func formatByteSize(n uint64) string {
+	if n == 0 {
+		return "0"
+	}
+	best, bestMult := "", uint64(1)
+	for _, unit := range []string{"KiB", "kB", "MiB", "MB", "GiB", "GB", "TiB", "TB", "PiB", "PB", "EiB", "EB"} {
+		mult := byteUnits[strings.ToLower(unit)]
+		if n%mult == 0 && mult > bestMult {
+			best, bestMult = unit, mult
+		}
+	}
+	return strconv.FormatUint(n/bestMult, 10) + best
+}
+
+// -- byte size Value
+type byteSizeValue uint64
+
+func newByteSizeValue(val uint64, p *uint64) *byteSizeValue {
+	*p = val
+	return (*byteSizeValue)(p)
+}
+
+func (b *byteSizeValue) Set(s string) error {
+	v, err := parseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = byteSizeValue(v)
+	return nil
+}
+
+func (b *byteSizeValue) Get() interface{} { return uint64(*b) }
+
+func (b *byteSizeValue) String() string { return formatByteSize(uint64(*b)) }
+
+// -- time.Time Value
+type timeValue struct {
+	p      *time.Time
+	layout string
+}
+
+func newTimeValue(val time.Time, p *time.Time, layout string) *timeValue {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	*p = val
+	return &timeValue{p, layout}
+}
+
+// Set parses s with the value's layout. The empty string is the zero time.
+func (t *timeValue) Set(s string) error {
+	if s == "" {
+		*t.p = time.Time{}
+		return nil
+	}
+	v, err := time.Parse(t.layout, s)
+	if err != nil {
+		return err
+	}
+	*t.p = v
+	return nil
+}
+
+func (t *timeValue) Get() interface{} { return *t.p }
+
+func (t *timeValue) save() func() {
+	v := *t.p
+	return func() { *t.p = v }
+}
+
+func (t *timeValue) String() string {
+	if t == nil || t.p == nil || t.p.IsZero() {
+		return ""
+	}
+	return t.p.Format(t.layout)
+}
+
+// -- percent Value
+type percentValue float64
+
+func newPercentValue(val float64, p *float64) *percentValue {
+	*p = val
+	return (*percentValue)(p)
+}
+
+func (f *percentValue) Set(s string) error {
+	s = strings.TrimSpace(s)
+	pct := strings.HasSuffix(s, "%")
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return errors.New("not a finite number")
+	}
+	if pct {
+		v /= 100
+	}
+	*f = percentValue(v)
+	return nil
+}
+
+func (f *percentValue) Get() interface{} { return float64(*f) }
+
+// String formats the fraction as a percentage with the fewest digits that
+// Set maps back to the same fraction.
+func (f *percentValue) String() string {
+	v := float64(*f)
+	for prec := 0; prec < 17; prec++ {
+		s := strconv.FormatFloat(v*100, 'f', prec, 64)
+		if p, err := strconv.ParseFloat(s, 64); err == nil && p/100 == v {
+			return s + "%"
+		}
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// A Rate is a number of events per period of time, such as 100/s.
+type Rate struct {
+	Count float64
+	Per   time.Duration
+}
+
+// rateUnits maps the duration units that may stand alone after the slash of
+// a rate to their durations.
+var rateUnits = []struct {
+	unit string
+	d    time.Duration
+}{
+	{"h", time.Hour},
+	{"m", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"ns", time.Nanosecond},
+}
+
+// PerSecond returns the rate as a number of events per second.
+func (r Rate) PerSecond() float64 {
+	if r.Per == 0 {
+		return 0
+	}
+	return r.Count / r.Per.Seconds()
+}
+
+// String formats the rate as count/period, such as 100/s or 5/1m30s, or
+// as the empty string if the rate is the zero Rate.
+func (r Rate) String() string {
+	if r == (Rate{}) {
+		return ""
+	}
+	per := r.Per.String()
+	for _, u := range rateUnits {
+		if r.Per == u.d {
+			per = u.unit
+			break
+		}
+	}
+	return strconv.FormatFloat(r.Count, 'g', -1, 64) + "/" + per
+}
+
+// Set parses s as count/period, where count is a non-negative number and
+// period is a positive duration acceptable to time.ParseDuration or a bare
+// unit such as s or h standing for one of that unit. The empty string is
+// the zero Rate. Set satisfies the Value interface.
+func (r *Rate) Set(s string) error {
+	if s == "" {
+		*r = Rate{}
+		return nil
+	}
+	i := strings.Index(s, "/")
+	if i < 0 {
+		return fmt.Errorf("%q is not of the form count/period", s)
+	}
+	count, err := strconv.ParseFloat(strings.TrimSpace(s[:i]), 64)
+	if err != nil {
+		return err
+	}
+	if count < 0 || math.IsNaN(count) || math.IsInf(count, 0) {
+		return errors.New("count must be a non-negative finite number")
+	}
+	period := strings.TrimSpace(s[i+1:])
+	var per time.Duration
+	for _, u := range rateUnits {
+		if period == u.unit {
+			per = u.d
+			break
+		}
+	}
+	if per == 0 {
+		if per, err = time.ParseDuration(period); err != nil {
+			return err
+		}
+		if per <= 0 {
+			return errors.New("period must be positive")
+		}
+	}
+	*r = Rate{count, per}
+	return nil
+}
+
+// Get returns the Rate, satisfying the Getter interface.
+func (r *Rate) Get() interface{} { return *r }
+
+// ByteSizeVar defines a byte size EnvVar with specified name, and default value.
+// The argument p points to a uint64 variable in which to store the number of bytes.
+// The EnvVar accepts a decimal number with an optional SI (kB, MB, ...) or IEC (KiB, MiB, ...) suffix.
+func (evs *EnvVarSet) ByteSizeVar(p *uint64, name string, value uint64) {
+	evs.Var(newByteSizeValue(value, p), name)
+}
+
+// ByteSizeVar defines a byte size EnvVar with specified name, and default value.
+// The argument p points to a uint64 variable in which to store the number of bytes.
+// The EnvVar accepts a decimal number with an optional SI (kB, MB, ...) or IEC (KiB, MiB, ...) suffix.
+func ByteSizeVar(p *uint64, name string, value uint64) {
+	EnvVars.ByteSizeVar(p, name, value)
+}
+
+// ByteSize defines a byte size EnvVar with specified name, and default value.
+// The return value is the address of a uint64 variable that stores the number of bytes.
+// The EnvVar accepts a decimal number with an optional SI (kB, MB, ...) or IEC (KiB, MiB, ...) suffix.
+func (evs *EnvVarSet) ByteSize(name string, value uint64) *uint64 {
+	p := new(uint64)
+	evs.ByteSizeVar(p, name, value)
+	return p
+}
+
+// ByteSize defines a byte size EnvVar with specified name, and default value.
+// The return value is the address of a uint64 variable that stores the number of bytes.
+// The EnvVar accepts a decimal number with an optional SI (kB, MB, ...) or IEC (KiB, MiB, ...) suffix.
+func ByteSize(name string, value uint64) *uint64 {
+	return EnvVars.ByteSize(name, value)
+}
+
+// TimeVar defines a time.Time EnvVar with specified name, default value, and layout.
+// The argument p points to a time.Time variable in which to store the value of the EnvVar.
+// The EnvVar accepts a time acceptable to time.Parse with layout, or time.RFC3339 if layout is empty.
+func (evs *EnvVarSet) TimeVar(p *time.Time, name string, value time.Time, layout string) {
+	evs.Var(newTimeValue(value, p, layout), name)
+}
+
+// TimeVar defines a time.Time EnvVar with specified name, default value, and layout.
+// The argument p points to a time.Time variable in which to store the value of the EnvVar.
+// The EnvVar accepts a time acceptable to time.Parse with layout, or time.RFC3339 if layout is empty.
+func TimeVar(p *time.Time, name string, value time.Time, layout string) {
+	EnvVars.TimeVar(p, name, value, layout)
+}
+
+// Time defines a time.Time EnvVar with specified name, default value, and layout.
+// The return value is the address of a time.Time variable that stores the value of the EnvVar.
+// The EnvVar accepts a time acceptable to time.Parse with layout, or time.RFC3339 if layout is empty.
+func (evs *EnvVarSet) Time(name string, value time.Time, layout string) *time.Time {
+	p := new(time.Time)
+	evs.TimeVar(p, name, value, layout)
+	return p
+}
+
+// Time defines a time.Time EnvVar with specified name, default value, and layout.
+// The return value is the address of a time.Time variable that stores the value of the EnvVar.
+// The EnvVar accepts a time acceptable to time.Parse with layout, or time.RFC3339 if layout is empty.
+func Time(name string, value time.Time, layout string) *time.Time {
+	return EnvVars.Time(name, value, layout)
+}
+
+// PercentVar defines a percentage EnvVar with specified name, and default value.
+// The argument p points to a float64 variable in which to store the value of the EnvVar as a fraction.
+// The EnvVar accepts a percentage such as 25%, stored as 0.25, or a bare fraction such as 0.25.
+func (evs *EnvVarSet) PercentVar(p *float64, name string, value float64) {
+	evs.Var(newPercentValue(value, p), name)
+}
+
+// PercentVar defines a percentage EnvVar with specified name, and default value.
+// The argument p points to a float64 variable in which to store the value of the EnvVar as a fraction.
+// The EnvVar accepts a percentage such as 25%, stored as 0.25, or a bare fraction such as 0.25.
+func PercentVar(p *float64, name string, value float64) {
+	EnvVars.PercentVar(p, name, value)
+}
+
+// Percent defines a percentage EnvVar with specified name, and default value.
+// The return value is the address of a float64 variable that stores the value of the EnvVar as a fraction.
+// The EnvVar accepts a percentage such as 25%, stored as 0.25, or a bare fraction such as 0.25.
+func (evs *EnvVarSet) Percent(name string, value float64) *float64 {
+	p := new(float64)
+	evs.PercentVar(p, name, value)
+	return p
+}
+
+// Percent defines a percentage EnvVar with specified name, and default value.
+// The return value is the address of a float64 variable that stores the value of the EnvVar as a fraction.
+// The EnvVar accepts a percentage such as 25%, stored as 0.25, or a bare fraction such as 0.25.
+func Percent(name string, value float64) *float64 {
+	return EnvVars.Percent(name, value)
+}
+
+// RateVar defines a Rate EnvVar with specified name, and default value.
+// The argument p points to a Rate variable in which to store the value of the EnvVar.
+// The EnvVar accepts a rate such as 100/s; see Rate.Set.
+func (evs *EnvVarSet) RateVar(p *Rate, name string, value Rate) {
+	*p = value
+	evs.Var(p, name)
+}
+
+// RateVar defines a Rate EnvVar with specified name, and default value.
+// The argument p points to a Rate variable in which to store the value of the EnvVar.
+// The EnvVar accepts a rate such as 100/s; see Rate.Set.
+func RateVar(p *Rate, name string, value Rate) {
+	EnvVars.RateVar(p, name, value)
+}
+
+// Rate defines a Rate EnvVar with specified name, and default value.
+// The return value is the address of a Rate variable that stores the value of the EnvVar.
+// The EnvVar accepts a rate such as 100/s; see Rate.Set.
+// As the type takes the name Rate, there is no top-level form; use EnvVars.Rate.
+func (evs *EnvVarSet) Rate(name string, value Rate) *Rate {
+	p := new(Rate)
+	evs.RateVar(p, name, value)
+	return p
+}
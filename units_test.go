@@ -0,0 +1,190 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want uint64
+		str  string
+	}{
+		{"0", 0, "0"},
+		{"512", 512, "512"},
+		{"10MiB", 10 << 20, "10MiB"},
+		{"10 mib", 10 << 20, "10MiB"},
+		{"1.5GiB", 3 << 29, "1536MiB"},
+		{"2kB", 2000, "2kB"},
+		{"2K", 2000, "2kB"},
+		{"2M", 2000000, "2MB"},
+		{"2G", 2000000000, "2GB"},
+		{"3t", 3e12, "3TB"},
+		{"1MB", 1000000, "1MB"},
+		{"1024000", 1024000, "1000KiB"},
+		{"100B", 100, "100"},
+		{"15EiB", 15 << 60, "15EiB"},
+		{"18446744073709551615", 1<<64 - 1, "18446744073709551615"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		size := evs.ByteSize("SIZE", 1)
+		if err := evs.Parse([]string{"SIZE=" + test.in}); err != nil {
+			t.Errorf("%q: %v", test.in, err)
+			continue
+		}
+		if *size != test.want {
+			t.Errorf("%q: got %d, want %d", test.in, *size, test.want)
+		}
+		if s := evs.Lookup("SIZE").Value.String(); s != test.str {
+			t.Errorf("%q: String() = %q, want %q", test.in, s, test.str)
+		}
+	}
+}
+
+func TestByteSizeErrors(t *testing.T) {
+	tests := []struct {
+		in    string
+		isErr error
+	}{
+		{"16EiB", strconv.ErrRange},
+		{"18446744073709551616", strconv.ErrRange},
+		{"-1", nil},
+		{"1.5", nil},
+		{"1.2.3MB", nil},
+		{"10QB", nil},
+		{"MiB", nil},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		size := evs.ByteSize("SIZE", 1)
+		err := evs.Parse([]string{"SIZE=" + test.in})
+		if err == nil {
+			t.Errorf("%q: expected error, got %d", test.in, *size)
+			continue
+		}
+		if test.isErr != nil && !errors.Is(err, test.isErr) {
+			t.Errorf("%q: got %v, want %v", test.in, err, test.isErr)
+		}
+		if *size != 1 {
+			t.Errorf("%q: rejected value changed variable to %d", test.in, *size)
+		}
+	}
+}
+
+func TestTime(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.SetOutput(ioutil.Discard)
+	start := evs.Time("START", time.Time{}, "")
+	day := evs.Time("DAY", time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC), "2006-01-02")
+	if def := evs.Lookup("START").DefValue; def != "" {
+		t.Errorf("zero default shown as %q", def)
+	}
+	if def := evs.Lookup("DAY").DefValue; def != "2017-01-01" {
+		t.Errorf("default shown as %q", def)
+	}
+	if err := evs.Parse([]string{"START=2026-01-01T00:00:00Z", "DAY=2026-03-04"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("START = %v, want %v", start, want)
+	}
+	if want := time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC); !day.Equal(want) {
+		t.Errorf("DAY = %v, want %v", day, want)
+	}
+	if err := evs.Parse([]string{"DAY=2026-03-04T00:00:00Z"}); err == nil {
+		t.Error("expected error for value not matching layout")
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		str  string
+	}{
+		{"25%", 0.25, "25%"},
+		{"0.25", 0.25, "25%"},
+		{" 7 % ", 0.07, "7%"},
+		{"150%", 1.5, "150%"},
+		{"0.1%", 0.001, "0.1%"},
+		{"12.5%", 0.125, "12.5%"},
+		{"-5%", -0.05, "-5%"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		pct := evs.Percent("RATE", 0)
+		if err := evs.Parse([]string{"RATE=" + test.in}); err != nil {
+			t.Errorf("%q: %v", test.in, err)
+			continue
+		}
+		if *pct != test.want {
+			t.Errorf("%q: got %v, want %v", test.in, *pct, test.want)
+		}
+		if s := evs.Lookup("RATE").Value.String(); s != test.str {
+			t.Errorf("%q: String() = %q, want %q", test.in, s, test.str)
+		}
+	}
+	for _, in := range []string{"x%", "NaN", "1e400%"} {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		evs.Percent("RATE", 0)
+		if err := evs.Parse([]string{"RATE=" + in}); err == nil {
+			t.Errorf("%q: expected error", in)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rate
+		str  string
+	}{
+		{"100/s", Rate{100, time.Second}, "100/s"},
+		{"5 / m", Rate{5, time.Minute}, "5/m"},
+		{"0.5/h", Rate{0.5, time.Hour}, "0.5/h"},
+		{"10/500ms", Rate{10, 500 * time.Millisecond}, "10/500ms"},
+		{"3/1m30s", Rate{3, 90 * time.Second}, "3/1m30s"},
+		{"60/1m", Rate{60, time.Minute}, "60/m"},
+	}
+	for _, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		r := evs.Rate("RATE", Rate{1, time.Second})
+		if err := evs.Parse([]string{"RATE=" + test.in}); err != nil {
+			t.Errorf("%q: %v", test.in, err)
+			continue
+		}
+		if *r != test.want {
+			t.Errorf("%q: got %v, want %v", test.in, *r, test.want)
+		}
+		if s := r.String(); s != test.str {
+			t.Errorf("%q: String() = %q, want %q", test.in, s, test.str)
+		}
+	}
+	if got := (Rate{30, time.Minute}).PerSecond(); got != 0.5 {
+		t.Errorf("PerSecond() = %v, want 0.5", got)
+	}
+	for _, in := range []string{"100", "x/s", "-1/s", "1/0s", "1/-1s", "1/fortnight"} {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		r := Rate{1, time.Second}
+		evs.RateVar(&r, "RATE", r)
+		if err := evs.Parse([]string{"RATE=" + in}); err == nil {
+			t.Errorf("%q: expected error, got %v", in, r)
+		} else if r != (Rate{1, time.Second}) {
+			t.Errorf("%q: rejected value changed rate to %v", in, r)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A Validator checks the value of an EnvVar after it has been set,
+// returning an error if the value is not acceptable. The value is that
+// returned by the Get method of the EnvVar's Value, or by its String method
+// if the Value does not satisfy Getter. For a Secret, it is the string
+// returned by Reveal.
+type Validator func(value interface{}) error
+
+// Validate adds validators to the named EnvVar, which must already be
+// defined. Parse and Set run the validators, in the order they were added,
+// after storing a value in the EnvVar and fail with the first error.
+func (evs *EnvVarSet) Validate(name string, validators ...Validator) {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+	envVar := evs.lookupDefined(name)
+	envVar.Validators = append(envVar.Validators, validators...)
+}
+
+// Validate adds validators to the named EnvVar in the default set.
+func Validate(name string, validators ...Validator) {
+	EnvVars.Validate(name, validators...)
+}
+
+// validate runs the validators of envVar against its value.
+func validate(envVar *EnvVar) error {
+	if len(envVar.Validators) == 0 {
+		return nil
+	}
+	var v interface{}
+	if g, ok := envVar.Value.(Getter); ok {
+		v = g.Get()
+	} else {
+		v = envVar.Value.String()
+	}
+	if s, ok := v.(Secret); ok {
+		v = s.Reveal()
+	}
+	for _, validator := range envVar.Validators {
+		if err := validator(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toFloat returns the value of a number of any integer or floating-point
+// type, including types such as time.Duration derived from them.
+func toFloat(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// Min returns a Validator requiring a numeric value to be at least min.
+func Min(min float64) Validator {
+	return func(v interface{}) error {
+		f, ok := toFloat(v)
+		if !ok {
+			return fmt.Errorf("%T is not a number", v)
+		}
+		if f < min {
+			return fmt.Errorf("%v is less than the minimum %v", v, min)
+		}
+		return nil
+	}
+}
+
+// Max returns a Validator requiring a numeric value to be at most max.
+func Max(max float64) Validator {
+	return func(v interface{}) error {
+		f, ok := toFloat(v)
+		if !ok {
+			return fmt.Errorf("%T is not a number", v)
+		}
+		if f > max {
+			return fmt.Errorf("%v is greater than the maximum %v", v, max)
+		}
+		return nil
+	}
+}
+
+// DurationBetween returns a Validator requiring a time.Duration value to lie
+// between min and max inclusive.
+func DurationBetween(min, max time.Duration) Validator {
+	return func(v interface{}) error {
+		d, ok := v.(time.Duration)
+		if !ok {
+			return fmt.Errorf("%T is not a time.Duration", v)
+		}
+		if d < min || d > max {
+			return fmt.Errorf("%v is not between %v and %v", d, min, max)
+		}
+		return nil
+	}
+}
+
+// OneOf returns a Validator requiring the value, formatted as by fmt.Sprint,
+// to be one of allowed.
+func OneOf(allowed ...string) Validator {
+	return func(v interface{}) error {
+		s := fmt.Sprint(v)
+		for _, a := range allowed {
+			if s == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", s, strings.Join(allowed, ", "))
+	}
+}
+
+// Matches returns a Validator requiring the value, formatted as by
+// fmt.Sprint, to match re.
+func Matches(re *regexp.Regexp) Validator {
+	return func(v interface{}) error {
+		if s := fmt.Sprint(v); !re.MatchString(s) {
+			return fmt.Errorf("%q does not match %s", s, re)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2017 Dyson Simmons. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package envvar_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/dyson/envvar"
+)
+
+func TestValidators(t *testing.T) {
+	even := func(v interface{}) error {
+		if v.(int)%2 != 0 {
+			return fmt.Errorf("%d is odd", v)
+		}
+		return nil
+	}
+	tests := []struct {
+		define func(evs *EnvVarSet)
+		value  string
+		want   string // error substring, empty for success
+	}{
+		{func(evs *EnvVarSet) { evs.Int("V", 0); evs.Validate("V", Min(1), Max(65535)) }, "8080", ""},
+		{func(evs *EnvVarSet) { evs.Int("V", 0); evs.Validate("V", Min(1), Max(65535)) }, "0", "less than the minimum 1"},
+		{func(evs *EnvVarSet) { evs.Int("V", 0); evs.Validate("V", Min(1), Max(65535)) }, "65536", "greater than the maximum 65535"},
+		{func(evs *EnvVarSet) { evs.Uint64("V", 0); evs.Validate("V", Max(10)) }, "11", "greater than the maximum"},
+		{func(evs *EnvVarSet) { evs.Float64("V", 0); evs.Validate("V", Min(0), Max(1)) }, "0.5", ""},
+		{func(evs *EnvVarSet) { evs.Float64("V", 0); evs.Validate("V", Min(0), Max(1)) }, "1.5", "greater than the maximum"},
+		{func(evs *EnvVarSet) { evs.String("V", ""); evs.Validate("V", Min(0)) }, "x", "string is not a number"},
+		{func(evs *EnvVarSet) { evs.String("V", ""); evs.Validate("V", OneOf("debug", "info")) }, "info", ""},
+		{func(evs *EnvVarSet) { evs.String("V", ""); evs.Validate("V", OneOf("debug", "info")) }, "trace", `"trace" is not one of debug, info`},
+		{func(evs *EnvVarSet) { evs.String("V", ""); evs.Validate("V", Matches(regexp.MustCompile(`^[a-z]+$`))) }, "abc", ""},
+		{func(evs *EnvVarSet) { evs.String("V", ""); evs.Validate("V", Matches(regexp.MustCompile(`^[a-z]+$`))) }, "ABC", "does not match"},
+		{func(evs *EnvVarSet) {
+			evs.Duration("V", 0)
+			evs.Validate("V", DurationBetween(time.Second, time.Minute))
+		}, "30s", ""},
+		{func(evs *EnvVarSet) {
+			evs.Duration("V", 0)
+			evs.Validate("V", DurationBetween(time.Second, time.Minute))
+		}, "2m", "is not between 1s and 1m0s"},
+		{func(evs *EnvVarSet) { evs.Duration("V", 0); evs.Validate("V", Min(float64(time.Second))) }, "1ms", "less than the minimum"},
+		{func(evs *EnvVarSet) { evs.Int("V", 0); evs.Validate("V", even) }, "3", "3 is odd"},
+		{func(evs *EnvVarSet) { evs.Var(&userVar{}, "V"); evs.Validate("V", OneOf("[a]")) }, "a", ""},
+	}
+	for i, test := range tests {
+		evs := NewEnvVarSet("test", ContinueOnError)
+		evs.SetOutput(ioutil.Discard)
+		test.define(evs)
+		err := evs.Parse([]string{"V=" + test.value})
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("%d: V=%s: unexpected error %v", i, test.value, err)
+			}
+			continue
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) || perr.Name != "V" || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%d: V=%s: want *ParseError containing %q, got %v", i, test.value, test.want, err)
+		}
+	}
+}
+
+func TestValidateSet(t *testing.T) {
+	evs := NewEnvVarSet("test", ContinueOnError)
+	evs.String("LEVEL", "info")
+	evs.Validate("LEVEL", OneOf("debug", "info"))
+	if err := evs.Set("LEVEL", "trace"); err == nil {
+		t.Error("Set(LEVEL, trace) succeeded")
+	}
+	if err := evs.Set("LEVEL", "debug"); err != nil {
+		t.Error(err)
+	}
+}